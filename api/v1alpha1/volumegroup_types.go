@@ -23,6 +23,7 @@ import (
 
 // VolumeGroupSpec defines the desired state of a VolumeGroup.
 // It contains various fields that specify how the volume group should be configured and managed.
+// +kubebuilder:validation:XValidation:rule="has(self.devicePreparation) == has(oldSelf.devicePreparation)",message="the device preparation cannot be added or removed once the volume group is created"
 type VolumeGroupSpec struct {
 	// NodeName is the name of the node where the volume group should be created.
 	// This field is immutable because the volume group is not movable between nodes.
@@ -45,6 +46,11 @@ type VolumeGroupSpec struct {
 	// This is done at runtime and after admission of the VolumeGroupSpec.
 	PhysicalVolumeSelector PhysicalVolumeSelector `json:"physicalVolumeSelector"`
 
+	// DevicePreparation prepares the devices matched by the PhysicalVolumeSelector before they become physical volumes.
+	// If not specified, the devices are used as physical volumes directly.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="the device preparation cannot be changed once set"
+	DevicePreparation *DevicePreparation `json:"devicePreparation,omitempty"`
+
 	// Tags is a list of tags to apply to the volume group.
 	// Tags are used to group volume groups and to apply policies to them.
 	// They can also be used on the host to apply policies to all volume groups with the same tag.
@@ -81,6 +87,22 @@ type VolumeGroupSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="the metadata size cannot be changed once set"
 	MetadataSize *resource.Quantity `json:"metadataSize,omitempty"`
 
+	// MetadataCopies is the number of metadata copies the volume group keeps on its physical volumes,
+	// corresponding to vgmetadatacopies. lvm2 ignores the metadata areas on the remaining physical volumes,
+	// which reduces the latency of metadata writes in volume groups with many physical volumes.
+	// If set to 0, the number of copies is unmanaged and all metadata areas are used unless they are ignored
+	// with PVSelectorTerm.MetadataIgnore. This value is changeable after the volume group is created.
+	// If not specified, the host default is used.
+	// +kubebuilder:validation:Minimum=0
+	MetadataCopies *int64 `json:"metadataCopies,omitempty"`
+
+	// MetadataProfile is the lvm2 metadata profile attached to the volume group, see lvm.conf(5).
+	// Metadata profiles tune e.g. the autoextend thresholds of thin pools or the allocation in the volume group.
+	// The profile is attached with vgchange --metadataprofile after the volume group is created,
+	// and changes of the profile or the attached profile are reported as drift.
+	// If not specified, the attached profile is not changed.
+	MetadataProfile *MetadataProfile `json:"metadataProfile,omitempty"`
+
 	// AllocationPolicy is the policy used to allocate extents in the volume group.
 	// If not set, the host default is used.
 	AllocationPolicy *AllocationPolicy `json:"allocationPolicy,omitempty"`
@@ -124,9 +146,501 @@ type VolumeGroupSpec struct {
 	// +kubebuilder:default=Fail
 	DeviceLossSynchronizationPolicy DeviceLossSynchronizationPolicy `json:"deviceLossSynchronizationPolicy,omitempty"`
 
+	// DeviceLossWaitForReturn configures DeviceLossSynchronizationPolicyWaitForReturn.
+	// If not specified, missing physical volumes are waited for 5 minutes before they are removed.
+	DeviceLossWaitForReturn *DeviceLossWaitForReturn `json:"deviceLossWaitForReturn,omitempty"`
+
+	// PhysicalVolumeReplacements replace missing physical volumes with new devices.
+	// For each replacement, the device is added to the volume group, the logical volumes on the missing physical volume
+	// are repaired onto it and the missing physical volume is removed from the volume group.
+	// The progress of each replacement is reported in VolumeGroupStatus.PhysicalVolumeReplacements.
+	// Replacements take precedence over DeviceLossSynchronizationPolicy for the physical volumes they replace.
+	// +listType=map
+	// +listMapKey=missingPhysicalVolume
+	PhysicalVolumeReplacements []PhysicalVolumeReplacement `json:"physicalVolumeReplacements,omitempty"`
+
 	// DeviceRemovalVolumePolicy controls how the volume group will be synchronized when devices are removed from the desired set of physical volumes.
 	// +kubebuilder:default=MoveAndReduce
 	DeviceRemovalVolumePolicy DeviceRemovalVolumePolicy `json:"deviceRemovalVolumePolicy,omitempty"`
+
+	// PhysicalVolumeMove controls how extents are moved off physical volumes that are removed from the volume group
+	// with DeviceRemovalVolumePolicyMoveAndReduce.
+	// If not specified, all extents of a physical volume are moved with a single pvmove without pauses.
+	PhysicalVolumeMove *PhysicalVolumeMoveOptions `json:"physicalVolumeMove,omitempty"`
+
+	// Timeouts overrides the timeouts of operations run against lvm2 for this volume group.
+	// Fields that are not specified fall back to the timeouts configured for the controller.
+	Timeouts *OperationTimeouts `json:"timeouts,omitempty"`
+
+	// SyncInterval overrides the interval in which the volume group is synced with the node.
+	// If set to 0, the volume group is only synced when the VolumeGroup changes or a change is reported by the host.
+	// If not specified, the interval configured for the controller is used, unless changes of the volume group
+	// on the node are already reported by a host event source, in which case no periodic sync is done.
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+
+	// DriftPolicy controls how differences between the spec and the volume group on the node are handled,
+	// no matter if they were caused by a change of the spec or by a change on the node outside of the controller.
+	// +kubebuilder:default=Correct
+	// +kubebuilder:validation:Enum=Correct;Report
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// Activation controls whether the logical volumes in the volume group are active on the node.
+	// If set to Active, all logical volumes are activated with vgchange -ay.
+	// If set to Inactive, all logical volumes are deactivated with vgchange -an, e.g. for maintenance windows
+	// or volume groups that should exist but not be used.
+	// The activation is converged on every sync. If not specified, the activation is not managed by the controller.
+	// +kubebuilder:validation:Enum=Active;Inactive
+	Activation *Activation `json:"activation,omitempty"`
+
+	// ActivationMode controls how the logical volumes of a shared volume group are activated with Activation set to Active.
+	// If set to Exclusive, the logical volumes can only be active on this node (vgchange -aey).
+	// If set to Shared, the logical volumes can be active on multiple nodes at once (vgchange -asy).
+	// If not specified, lvm2 decides based on the lock type of the volume group.
+	// +kubebuilder:validation:Enum=Exclusive;Shared
+	ActivationMode *ActivationMode `json:"activationMode,omitempty"`
+
+	// LogicalVolumeActivationPolicy controls whether inactive logical volumes in the volume group are activated
+	// if Activation is not specified, e.g. after a reboot of the node with AutoActivation disabled.
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;OnStartup;Always
+	LogicalVolumeActivationPolicy LogicalVolumeActivationPolicy `json:"logicalVolumeActivationPolicy,omitempty"`
+
+	// Exported prepares the volume group for moving its disks to another node.
+	// If set to true, the logical volumes are deactivated and the volume group is exported with vgexport,
+	// after which it is not changed by the controller anymore. If the VolumeGroup is deleted while the volume group
+	// is exported, the volume group is not removed from the node, so that its disks can be moved to another node
+	// and imported there with Import. If set back to false, the volume group is imported again on the same node.
+	Exported bool `json:"exported,omitempty"`
+
+	// Import adopts an exported volume group on the node instead of creating a new one,
+	// e.g. after its disks were moved from another node where it was exported.
+	// The volume group is imported with vgimport and renamed to the name on the node if necessary.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="the import cannot be changed once set"
+	Import *VolumeGroupImport `json:"import,omitempty"`
+
+	// Shared makes the volume group a shared volume group coordinated by lvmlockd, e.g. on SAN-backed disks
+	// that are visible on several nodes. The volume group is created on NodeName and converted to the lock type there.
+	// The lockspace of the volume group is started on NodeName and all nodes in Shared.NodeNames.
+	// lvmlockd and the lock manager of the lock type have to be running on all of these nodes.
+	Shared *SharedVolumeGroup `json:"shared,omitempty"`
+
+	// SystemIDPolicy manages the lvm2 system ID of the volume group, see lvmsystemid(7).
+	// A volume group with a system ID can only be used on the host with the same system ID,
+	// which protects it from being activated on other hosts when its disks are visible on several hosts.
+	// The system ID is verified on every sync. As the volume group would become invisible to the node otherwise,
+	// the system ID is only set if it matches the system ID of the node as configured in lvm.conf.
+	// If not specified, the system ID is not managed by the controller.
+	SystemIDPolicy *SystemIDPolicy `json:"systemIDPolicy,omitempty"`
+
+	// MetadataBackup stores backups of the lvm2 metadata of the volume group taken with vgcfgbackup in the cluster,
+	// so that the volume group can be restored with vgcfgrestore even if the backups on the node are lost.
+	// A backup is taken whenever the metadata changed and periodically.
+	// If not specified, no backups are stored in the cluster.
+	MetadataBackup *MetadataBackup `json:"metadataBackup,omitempty"`
+
+	// ThinPools are thin pools created in the volume group, e.g. for TopoLVM device classes with thin provisioning.
+	// Thin pools are created and grown to their size, but never shrunk or removed by the controller.
+	// +listType=map
+	// +listMapKey=name
+	ThinPools []ThinPool `json:"thinPools,omitempty"`
+
+	// ReservedCapacity is the capacity kept free in the volume group as a safety margin, e.g. for snapshots or
+	// pvmove. It is not part of VolumeGroupStatus.Allocatable, which is used for publishing the capacity of the
+	// volume group, and the ReserveViolated condition is set if less capacity than reserved is free.
+	// If not specified, no capacity is reserved.
+	ReservedCapacity *ReservedCapacity `json:"reservedCapacity,omitempty"`
+
+	// CSIStorageCapacity publishes the allocatable capacity of the volume group in a CSIStorageCapacity
+	// for each matching StorageClass, which enables storage capacity aware scheduling of TopoLVM volumes.
+	// If not specified, no CSIStorageCapacity is published.
+	CSIStorageCapacity *CSIStorageCapacityPublishing `json:"csiStorageCapacity,omitempty"`
+
+	// Cache accelerates logical volumes of the volume group with a cache on fast physical volumes of the same
+	// volume group, e.g. NVMe devices in a volume group of HDDs.
+	// If not specified, caches are not managed by the controller.
+	Cache *VolumeGroupCache `json:"cache,omitempty"`
+
+	// DeviceHealth monitors the health of the devices of the physical volumes, which is reported in
+	// PhysicalVolumeStatus.Health. If not specified, the health of the devices is not monitored.
+	DeviceHealth *DeviceHealth `json:"deviceHealth,omitempty"`
+
+	// TopoLVM opts the volume group into the lvmd configuration rendered for its node,
+	// which lists a device class for the volume group under its name on the node.
+	// If not specified, the volume group is not part of the lvmd configuration.
+	TopoLVM *TopoLVMDeviceClass `json:"topolvm,omitempty"`
+}
+
+// CSIStorageCapacityPublishing controls which StorageClasses the capacity of a volume group is published for.
+// The CSIStorageCapacity objects are created in the namespace of the VolumeGroup and owned by it.
+type CSIStorageCapacityPublishing struct {
+	// Provisioner is the provisioner of the StorageClasses the capacity is published for.
+	// +kubebuilder:default=topolvm.io
+	Provisioner string `json:"provisioner,omitempty"`
+
+	// MatchParameters are the parameters a StorageClass needs to have for the capacity to be published for it.
+	// If not specified, StorageClasses with the parameter topolvm.io/device-class set to the device class
+	// in VolumeGroupSpec.TopoLVM are matched. Without TopoLVM, no StorageClass is matched, as the capacity of
+	// the volume group would otherwise be published for StorageClasses it does not back.
+	// +optional
+	MatchParameters map[string]string `json:"matchParameters,omitempty"`
+
+	// TopologyKey is the key of the Node label with the node name that forms the topology segment of the capacity.
+	// +kubebuilder:default=topology.topolvm.io/node
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// CacheMode is the mode of the cache of a logical volume, see lvmcache(7).
+type CacheMode string
+
+const (
+	// CacheModeWritethrough caches reads with dm-cache, writes go to the cache and the origin.
+	// See VolumeGroupCache for more information.
+	CacheModeWritethrough CacheMode = "Writethrough"
+	// CacheModeWriteback caches reads and writes with dm-cache, writes go to the origin later.
+	// See VolumeGroupCache for more information.
+	CacheModeWriteback CacheMode = "Writeback"
+	// CacheModeWritecache caches writes only with dm-writecache.
+	// See VolumeGroupCache for more information.
+	CacheModeWritecache CacheMode = "Writecache"
+)
+
+// VolumeGroupCache controls the caches of logical volumes in a volume group.
+// lvm2 has no cache pool shared by several logical volumes, as a cache volume is attached to exactly one logical
+// volume. Instead, the physical volumes with PhysicalVolumeTag form the pool the cache volumes are allocated from:
+// a cache volume of Size is created on them for each cached logical volume and attached to it with lvconvert.
+// The capacity used on the cache devices is Size times the number of cached logical volumes, and logical volumes
+// are left uncached with a failed sync once the cache devices are full.
+// Caches are never removed by the controller, they are only detached to change between dm-cache and dm-writecache.
+type VolumeGroupCache struct {
+	// PhysicalVolumeTag is the tag of the physical volumes the cache volumes are allocated on,
+	// e.g. added through PVSelectorTerm.Tags.
+	// +kubebuilder:validation:MinLength=1
+	PhysicalVolumeTag string `json:"physicalVolumeTag"`
+
+	// Mode is the mode of the caches. Writethrough and Writeback are changed with lvchange for attached caches.
+	// Changing from or to Writecache detaches the cache volume with lvconvert --splitcache, which writes back
+	// dirty blocks first, and attaches it again in the new mode.
+	// +kubebuilder:default=Writethrough
+	// +kubebuilder:validation:Enum=Writethrough;Writeback;Writecache
+	Mode CacheMode `json:"mode,omitempty"`
+
+	// Size is the size of the cache volume of each cached logical volume, not of all caches together.
+	// Changing it only applies to caches attached afterwards.
+	Size resource.Quantity `json:"size"`
+
+	// LogicalVolumes are the names of the logical volumes that are cached.
+	// +optional
+	// +listType=set
+	LogicalVolumes []string `json:"logicalVolumes,omitempty"`
+
+	// LogicalVolumeTag caches all logical volumes with the tag, e.g. logical volumes provisioned by TopoLVM
+	// with a tag from the lvcreate options of their device class.
+	// +optional
+	LogicalVolumeTag string `json:"logicalVolumeTag,omitempty"`
+}
+
+// CachedLogicalVolumeStatus reports the cache of a logical volume.
+type CachedLogicalVolumeStatus struct {
+	// Name is the name of the cached logical volume.
+	Name string `json:"name"`
+
+	// Mode is the mode of the cache.
+	Mode CacheMode `json:"mode,omitempty"`
+
+	// CacheVolume is the name of the cache volume attached to the logical volume.
+	CacheVolume string `json:"cacheVolume,omitempty"`
+
+	// ReadHits is the number of reads served by the cache. Corresponds to cache_read_hits.
+	ReadHits int64 `json:"readHits,omitempty"`
+	// ReadMisses is the number of reads not served by the cache. Corresponds to cache_read_misses.
+	ReadMisses int64 `json:"readMisses,omitempty"`
+	// WriteHits is the number of writes to blocks in the cache. Corresponds to cache_write_hits.
+	WriteHits int64 `json:"writeHits,omitempty"`
+	// WriteMisses is the number of writes to blocks not in the cache. Corresponds to cache_write_misses.
+	WriteMisses int64 `json:"writeMisses,omitempty"`
+	// DirtyBlocks is the number of blocks in the cache not yet written to the origin.
+	// Corresponds to cache_dirty_blocks or writecache_writeback_blocks.
+	DirtyBlocks int64 `json:"dirtyBlocks,omitempty"`
+}
+
+// DevicePreparation prepares devices before they become physical volumes.
+type DevicePreparation struct {
+	// Encryption encrypts the devices with LUKS2 and uses their dm-crypt mappings as physical volumes.
+	// If not specified, the devices are not encrypted.
+	Encryption *DeviceEncryption `json:"encryption,omitempty"`
+}
+
+const (
+	// EncryptionPassphraseKey is the key of the passphrase in the Secret referenced by DeviceEncryption.
+	EncryptionPassphraseKey = "passphrase"
+	// EncryptionPreviousPassphraseKey is the key of the previous passphrase in the Secret referenced by DeviceEncryption,
+	// which is replaced by the passphrase on all devices when the passphrase is rotated.
+	EncryptionPreviousPassphraseKey = "previousPassphrase"
+)
+
+// DeviceEncryption encrypts devices with LUKS2 using a passphrase from a Secret.
+// Devices that are not formatted with LUKS yet are formatted before they are added to the volume group,
+// which destroys all data on them. The dm-crypt mappings are opened as /dev/mapper/topovgm-<LUKS UUID>
+// and reopened when the controller starts, e.g. after a reboot of the node.
+// To rotate the passphrase, move it to the previousPassphrase key of the Secret and set the new passphrase,
+// and the key slots of all devices are replaced one by one without losing data.
+type DeviceEncryption struct {
+	// SecretName is the name of the Secret in the namespace of the VolumeGroup with the passphrase
+	// under the key passphrase and optionally the previous passphrase under the key previousPassphrase.
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// EncryptionStatus reports the encrypted devices of a volume group.
+type EncryptionStatus struct {
+	// Devices are the encrypted devices of the volume group.
+	// +listType=map
+	// +listMapKey=device
+	Devices []EncryptedDeviceStatus `json:"devices,omitempty"`
+
+	// KeyVersion is the resource version of the Secret whose passphrase opens all devices.
+	// A different resource version of the Secret triggers the rotation of the passphrase.
+	KeyVersion string `json:"keyVersion,omitempty"`
+}
+
+// EncryptedDeviceStatus reports an encrypted device of a volume group.
+type EncryptedDeviceStatus struct {
+	// Device is the path of the encrypted device.
+	Device string `json:"device"`
+	// UUID is the UUID of the LUKS header of the device.
+	UUID string `json:"uuid"`
+	// Mapping is the path of the dm-crypt mapping used as physical volume.
+	Mapping string `json:"mapping"`
+}
+
+// FailingDevicePolicy is the policy for physical volumes whose devices are failing.
+type FailingDevicePolicy string
+
+const (
+	// FailingDevicePolicyNone only reports failing devices.
+	// See DeviceHealth for more information.
+	FailingDevicePolicyNone FailingDevicePolicy = "None"
+	// FailingDevicePolicyMarkNonAllocatable marks the physical volumes of failing devices as non-allocatable
+	// with pvchange --allocatable n, so that no new extents are allocated on them. They are never marked as
+	// allocatable again by the controller.
+	// See DeviceHealth for more information.
+	FailingDevicePolicyMarkNonAllocatable FailingDevicePolicy = "MarkNonAllocatable"
+)
+
+// DeviceHealth controls the monitoring of the health of devices.
+// The health is derived from the I/O error counters of the devices in sysfs, the state of device-mapper devices
+// and the SMART overall health self-assessment if smartctl is available on the node.
+type DeviceHealth struct {
+	// IOErrorThreshold is the number of I/O errors since boot at which a device is considered failing.
+	// Devices with fewer I/O errors are considered degraded.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	IOErrorThreshold int64 `json:"ioErrorThreshold,omitempty"`
+
+	// FailingDevicePolicy is the policy for physical volumes whose devices are failing.
+	// +kubebuilder:default=None
+	// +kubebuilder:validation:Enum=None;MarkNonAllocatable
+	FailingDevicePolicy FailingDevicePolicy `json:"failingDevicePolicy,omitempty"`
+}
+
+// PhysicalVolumeHealthState is the health of the device of a physical volume.
+type PhysicalVolumeHealthState string
+
+const (
+	// PhysicalVolumeHealthy means no health signal indicates a problem with the device.
+	PhysicalVolumeHealthy PhysicalVolumeHealthState = "Healthy"
+	// PhysicalVolumeDegraded means the device had I/O errors below the threshold or is a suspended device-mapper device.
+	PhysicalVolumeDegraded PhysicalVolumeHealthState = "Degraded"
+	// PhysicalVolumeFailing means the device reached the I/O error threshold or failed the SMART self-assessment.
+	PhysicalVolumeFailing PhysicalVolumeHealthState = "Failing"
+	// PhysicalVolumeHealthUnknown means the health signals of the device could not be read.
+	PhysicalVolumeHealthUnknown PhysicalVolumeHealthState = "Unknown"
+)
+
+// PhysicalVolumeHealth reports the health of the device of a physical volume.
+type PhysicalVolumeHealth struct {
+	// State is the health of the device derived from the signals below.
+	State PhysicalVolumeHealthState `json:"state"`
+
+	// IOErrors is the number of I/O errors of the device since boot. For device-mapper devices,
+	// e.g. encrypted devices, it is the sum of the errors of the underlying devices.
+	IOErrors int64 `json:"ioErrors,omitempty"`
+
+	// Suspended is true if the device is a suspended device-mapper device.
+	Suspended bool `json:"suspended,omitempty"`
+
+	// SMARTPassed is the result of the SMART overall health self-assessment, if available.
+	SMARTPassed *bool `json:"smartPassed,omitempty"`
+
+	// Message describes why the device is not healthy.
+	Message string `json:"message,omitempty"`
+}
+
+// ReservedCapacity is capacity reserved in a volume group, either as an absolute size or in percent of its size.
+// +kubebuilder:validation:XValidation:rule="has(self.size) != has(self.percent)",message="exactly one of size and percent is required"
+type ReservedCapacity struct {
+	// Size is the reserved capacity.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Percent is the reserved capacity in percent of the size of the volume group.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent *int32 `json:"percent,omitempty"`
+}
+
+// TopoLVMDeviceClassType is the type of the logical volumes TopoLVM provisions in a device class.
+type TopoLVMDeviceClassType string
+
+const (
+	// TopoLVMDeviceClassTypeThick provisions thick logical volumes.
+	// See TopoLVMDeviceClass for more information.
+	TopoLVMDeviceClassTypeThick TopoLVMDeviceClassType = "thick"
+	// TopoLVMDeviceClassTypeThin provisions thin logical volumes in a thin pool.
+	// See TopoLVMDeviceClass for more information.
+	TopoLVMDeviceClassTypeThin TopoLVMDeviceClassType = "thin"
+)
+
+// TopoLVMDeviceClass is the device class of lvmd, the node daemon of TopoLVM, backed by the volume group.
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'thin' || has(self.thinPool)",message="thinPool is required if the type is thin"
+type TopoLVMDeviceClass struct {
+	// Name is the name of the device class, which is referenced by the StorageClasses of TopoLVM.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Default marks the device class as the default device class of the node.
+	// At most one volume group per node should be the default.
+	// +optional
+	Default bool `json:"default,omitempty"`
+
+	// Type is the type of the logical volumes provisioned in the device class.
+	// +kubebuilder:default=thick
+	// +kubebuilder:validation:Enum=thick;thin
+	Type TopoLVMDeviceClassType `json:"type,omitempty"`
+
+	// SpareGB is the capacity in GiB kept free in the volume group by TopoLVM. Corresponds to spare-gb.
+	// If not specified, VolumeGroupSpec.ReservedCapacity rounded up to GiB is used if set,
+	// and the lvmd default of 10 GiB otherwise.
+	// +kubebuilder:validation:Minimum=0
+	SpareGB *int64 `json:"spareGB,omitempty"`
+
+	// ThinPool is the thin pool logical volumes are provisioned in with the type thin.
+	ThinPool *TopoLVMThinPool `json:"thinPool,omitempty"`
+
+	// LVCreateOptions are additional options passed to lvcreate by lvmd. Corresponds to lvcreate-options.
+	// +optional
+	LVCreateOptions []string `json:"lvcreateOptions,omitempty"`
+}
+
+// TopoLVMThinPool references a thin pool of the volume group for a device class of type thin.
+type TopoLVMThinPool struct {
+	// Name is the name of the thin pool, usually one of VolumeGroupSpec.ThinPools.
+	Name string `json:"name"`
+
+	// OverprovisionRatio is the ratio of the capacity of the thin logical volumes to the size of the thin pool.
+	// Corresponds to overprovision-ratio.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	OverprovisionRatio string `json:"overprovisionRatio"`
+}
+
+// ThinPool is a thin pool logical volume in a volume group.
+// +kubebuilder:validation:XValidation:rule="has(self.size) != has(self.percent)",message="exactly one of size and percent is required"
+type ThinPool struct {
+	// Name is the name of the thin pool logical volume.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.+-]+$`
+	Name string `json:"name"`
+
+	// Size is the size of the data of the thin pool, rounded up to the extent size of the volume group.
+	// With Autoextend, the size and the metadata size are minimums, as lvm2 grows the thin pool beyond them.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Percent is the size of the data of the thin pool in percent of the size of the volume group.
+	// The metadata of the thin pool and its spare are allocated in addition to the data,
+	// so the volume group needs free extents beyond the percentage.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percent *int32 `json:"percent,omitempty"`
+
+	// ChunkSize is the chunk size of the thin pool. If not specified, lvm2 chooses the chunk size.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="the chunk size cannot be changed once set"
+	ChunkSize *resource.Quantity `json:"chunkSize,omitempty"`
+
+	// MetadataSize is the size of the metadata of the thin pool. The metadata is grown if the size is increased.
+	// If not specified, lvm2 chooses the metadata size.
+	MetadataSize *resource.Quantity `json:"metadataSize,omitempty"`
+
+	// Autoextend extends the thin pool automatically by dmeventd once its usage passes a threshold.
+	// It is configured through a metadata profile named topovgm-<volume group>-<thin pool> that is installed
+	// on the node and attached to the thin pool. If not specified, the host defaults are used.
+	Autoextend *ThinPoolAutoextend `json:"autoextend,omitempty"`
+}
+
+// ThinPoolAutoextend configures the automatic extension of a thin pool, see lvmthin(7).
+type ThinPoolAutoextend struct {
+	// Threshold is the usage of the data or metadata of the thin pool in percent at which it is extended.
+	// Corresponds to activation/thin_pool_autoextend_threshold.
+	// +kubebuilder:validation:Minimum=50
+	// +kubebuilder:validation:Maximum=100
+	Threshold int32 `json:"threshold"`
+
+	// Percent is the percentage of its size the thin pool is extended by.
+	// Corresponds to activation/thin_pool_autoextend_percent.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	Percent int32 `json:"percent,omitempty"`
+}
+
+// ThinPoolStatus reports the state of a thin pool in the volume group.
+type ThinPoolStatus struct {
+	// Name is the name of the thin pool logical volume.
+	Name string `json:"name"`
+
+	// Size is the size of the data of the thin pool.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// MetadataSize is the size of the metadata of the thin pool.
+	MetadataSize *resource.Quantity `json:"metadataSize,omitempty"`
+
+	// DataPercent is the usage of the data of the thin pool in percent. Corresponds to data_percent.
+	DataPercent string `json:"dataPercent,omitempty"`
+
+	// MetadataPercent is the usage of the metadata of the thin pool in percent. Corresponds to metadata_percent.
+	MetadataPercent string `json:"metadataPercent,omitempty"`
+}
+
+// OperationTimeouts are timeouts of operations run against lvm2, separated by the kind of operation.
+// A timeout of 0 disables the timeout for the kind of operation.
+type OperationTimeouts struct {
+	// Discovery is the timeout for reading the state of the volume group and its devices from the node,
+	// e.g. with vgs, pvs or lsblk.
+	Discovery *metav1.Duration `json:"discovery,omitempty"`
+
+	// Mutation is the timeout for a single operation changing the volume group without touching the
+	// data on its devices, e.g. vgchange, vgrename or vgreduce.
+	Mutation *metav1.Duration `json:"mutation,omitempty"`
+
+	// LongOperation is the timeout for a single operation that initializes or wipes devices and scales
+	// with their number and size, e.g. vgcreate, vgextend or vgremove.
+	LongOperation *metav1.Duration `json:"longOperation,omitempty"`
+}
+
+// PhysicalVolumeMoveOptions controls how extents are moved off physical volumes with pvmove.
+// Moves are always run in the background and tracked in VolumeGroupStatus.PhysicalVolumeMoves.
+// Because lvm2 keeps track of the move in the volume group metadata, a move is resumed after a restart of the node.
+// The extents can be moved in chunks with pauses between them, which spreads the I/O of moving a physical volume
+// over time. This does not limit the bandwidth of a move: the data of a chunk is copied by the kernel as fast as
+// the devices allow, so smaller chunks bound how long the devices are busy with a single move.
+type PhysicalVolumeMoveOptions struct {
+	// MaximumExtentsPerMove is the maximum number of extents moved by a single pvmove. The remaining extents are
+	// moved by subsequent pvmoves until the physical volume is empty.
+	// If set to 0 or omitted, all extents of a physical volume are moved with a single pvmove.
+	// +kubebuilder:validation:Minimum=0
+	MaximumExtentsPerMove *int64 `json:"maximumExtentsPerMove,omitempty"`
+
+	// PauseBetweenMoves is the pause between two subsequent pvmoves of the same physical volume,
+	// which gives the devices time to serve other I/O between chunks of MaximumExtentsPerMove.
+	// If not specified, the next pvmove is started as soon as the previous one has completed.
+	PauseBetweenMoves *metav1.Duration `json:"pauseBetweenMoves,omitempty"`
 }
 
 // VolumeGroupStatus defines the observed state of VolumeGroup in lvm2.
@@ -171,6 +685,9 @@ type VolumeGroupStatus struct {
 	// Free is the total amount of free space in the volume group.
 	// Corresponds to vg_free.
 	Free *resource.Quantity `json:"free,omitempty"`
+	// Allocatable is the free space in the volume group that is not reserved by VolumeGroupSpec.ReservedCapacity.
+	// It is never negative and used instead of Free wherever the capacity of the volume group is published.
+	Allocatable *resource.Quantity `json:"allocatable,omitempty"`
 
 	// PhysicalVolumeCount is the number of physical volumes in the volume group.
 	// Corresponds to pv_count.
@@ -178,6 +695,11 @@ type VolumeGroupStatus struct {
 	// MissingPhysicalVolumeCount is the number of physical volumes in the volume group which are missing.
 	// Corresponds to vg_missing_pv_count.
 	MissingPhysicalVolumeCount int64 `json:"missingPhysicalVolumeCount,omitempty"`
+	// MissingPhysicalVolumes are the physical volumes found missing by the last syncs.
+	// An entry is removed once the physical volume returned or was removed from the volume group.
+	// +listType=map
+	// +listMapKey=uuid
+	MissingPhysicalVolumes []MissingPhysicalVolumeStatus `json:"missingPhysicalVolumes,omitempty"`
 	// MaximumPhysicalVolumes is the maximum number of physical volumes allowed in the volume group.
 	// Corresponds to max_pv.
 	MaximumPhysicalVolumes int64 `json:"maximumPhysicalVolumes,omitempty"`
@@ -199,6 +721,65 @@ type VolumeGroupStatus struct {
 	// MetadataAreaUsedCount is the number of metadata areas in use on the volume group.
 	// Corresponds to vg_mda_used_count.
 	MetadataAreaUsedCount int64 `json:"metadataAreaUsedCount,omitempty"`
+	// MetadataCopies is the number of metadata copies the volume group keeps, which is 0 if it is unmanaged.
+	// Corresponds to vg_mda_copies.
+	MetadataCopies int64 `json:"metadataCopies,omitempty"`
+	// MetadataProfile is the name of the metadata profile attached to the volume group.
+	// Corresponds to vg_profile.
+	MetadataProfile string `json:"metadataProfile,omitempty"`
+
+	// PhysicalVolumeMoves reports the progress of extents being moved off physical volumes that are removed
+	// from the volume group. An entry is removed once the physical volume has been removed from the volume group.
+	PhysicalVolumeMoves []PhysicalVolumeMoveStatus `json:"physicalVolumeMoves,omitempty"`
+
+	// PhysicalVolumeReplacements reports the progress of the replacements of missing physical volumes.
+	// +listType=map
+	// +listMapKey=missingPhysicalVolume
+	PhysicalVolumeReplacements []PhysicalVolumeReplacementStatus `json:"physicalVolumeReplacements,omitempty"`
+
+	// Activation is the activation state of the logical volumes in the volume group, derived from their attributes.
+	// It is Active if all logical volumes are active, Inactive if none of them are, and PartiallyActive otherwise.
+	// It is empty if the volume group has no logical volumes.
+	// Corresponds to the state in lv_attr.
+	Activation Activation `json:"activation,omitempty"`
+
+	// Exported is true if the volume group is exported on the node.
+	// Corresponds to the export state in vg_attr.
+	Exported bool `json:"exported,omitempty"`
+
+	// LockType is the lock type of the volume group, which is none for volume groups that are not shared.
+	// Corresponds to vg_lock_type.
+	LockType string `json:"lockType,omitempty"`
+
+	// Locks reports the state of the lockspace of a shared volume group on each node it is used on.
+	// Each entry is maintained by the controller running on the node.
+	// +listType=map
+	// +listMapKey=nodeName
+	Locks []NodeLockStatus `json:"locks,omitempty"`
+
+	// MetadataBackup reports the backups of the lvm2 metadata stored in the cluster.
+	MetadataBackup *MetadataBackupStatus `json:"metadataBackup,omitempty"`
+
+	// Encryption reports the encrypted devices of the volume group.
+	Encryption *EncryptionStatus `json:"encryption,omitempty"`
+
+	// Cache reports the cached logical volumes in the volume group and their cache statistics.
+	// +listType=map
+	// +listMapKey=name
+	Cache []CachedLogicalVolumeStatus `json:"cache,omitempty"`
+
+	// ThinPools reports the thin pools in the volume group declared in the spec.
+	// +listType=map
+	// +listMapKey=name
+	ThinPools []ThinPoolStatus `json:"thinPools,omitempty"`
+
+	// Drift lists the differences between the spec and the volume group on the node found by the last sync
+	// that were not corrected because of DriftPolicyReport. It is empty with DriftPolicyCorrect.
+	Drift []VolumeGroupDrift `json:"drift,omitempty"`
+
+	// PlannedOperations lists the lvm2 operations the controller would run to sync the volume group with the spec
+	// while the VolumeGroup is in plan mode. It is empty if the VolumeGroup is not in plan mode.
+	PlannedOperations []PlannedOperation `json:"plannedOperations,omitempty"`
 
 	// Conditions represent the latest available observations of an object's state.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -229,6 +810,10 @@ type PhysicalVolumeStatus struct {
 	// Corresponds to pv_minor.
 	Minor int64 `json:"minor"`
 
+	// Health is the health of the device of the physical volume, which is only reported if
+	// VolumeGroupSpec.DeviceHealth is set.
+	Health *PhysicalVolumeHealth `json:"health,omitempty"`
+
 	// Size is the size of the physical volume.
 	// Corresponds to pv_size.
 	Size *resource.Quantity `json:"size"`
@@ -274,6 +859,181 @@ type PhysicalVolumeStatus struct {
 	DeviceIDType string `json:"deviceIDType,omitempty"`
 }
 
+// PhysicalVolumeMoveStatus is the progress of extents being moved off a physical volume with pvmove.
+type PhysicalVolumeMoveStatus struct {
+	// Name is the name of the physical volume that extents are moved off.
+	Name string `json:"name"`
+
+	// StartTime is the time the first pvmove of the physical volume was started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// LastCompletionTime is the time the last pvmove of the physical volume was observed as completed.
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+
+	// Active is true while a pvmove of the physical volume is running on the node.
+	Active bool `json:"active"`
+
+	// TotalExtents is the number of allocated extents on the physical volume when the first pvmove was started.
+	TotalExtents int64 `json:"totalExtents"`
+
+	// RemainingExtents is the number of extents that are still allocated on the physical volume.
+	RemainingExtents int64 `json:"remainingExtents"`
+
+	// CurrentMoveExtents is the number of extents moved by the currently active pvmove.
+	CurrentMoveExtents int64 `json:"currentMoveExtents,omitempty"`
+
+	// Percent is the overall progress of moving all extents off the physical volume, including the
+	// progress of the currently active pvmove, as a decimal number between 0 and 100.
+	Percent string `json:"percent"`
+}
+
+// SystemIDPolicy controls the system ID of a volume group.
+// +kubebuilder:validation:XValidation:rule="!has(self.source) || self.source != 'Value' || has(self.value)",message="value is required if the source is Value"
+type SystemIDPolicy struct {
+	// Source is the source of the system ID of the volume group.
+	// If set to Host, the system ID of the node as configured in lvm.conf is used.
+	// If set to NodeName, the name of the node is used. This requires the system ID of the node to be the name of
+	// the node, e.g. with system_id_source = "lvmlocal" and system_id set to the name of the node in lvmlocal.conf,
+	// as every lvm2 command on the node, e.g. of lvmd, only uses volume groups with the system ID of the node.
+	// If set to Value, Value is used, which likewise has to be the system ID of the node.
+	// The system ID is never set if it does not match the system ID of the node.
+	// +kubebuilder:default=Host
+	// +kubebuilder:validation:Enum=NodeName;Host;Value
+	Source SystemIDSource `json:"source,omitempty"`
+
+	// Value is the system ID of the volume group if Source is set to Value.
+	Value string `json:"value,omitempty"`
+
+	// TakeoverFrom is the system ID of another host to take the volume group over from,
+	// e.g. after a node was replaced or the disks of the volume group were moved to this node.
+	// If the volume group is not visible on the node because it is owned by this system ID,
+	// its system ID is changed to the one of this node.
+	TakeoverFrom string `json:"takeoverFrom,omitempty"`
+}
+
+// MetadataProfile references an lvm2 metadata profile.
+type MetadataProfile struct {
+	// Name is the name of the profile in the profile directory of the node (usually /etc/lvm/profile),
+	// without the .profile suffix.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.+-]+$`
+	Name string `json:"name"`
+
+	// ConfigMap references the content of the profile in a ConfigMap in the namespace of the VolumeGroup.
+	// If set, the profile is installed into the profile directory of the node and kept up to date with the ConfigMap.
+	// Otherwise, the profile has to be installed on the node.
+	// +optional
+	ConfigMap *ProfileConfigMapReference `json:"configMap,omitempty"`
+}
+
+// ProfileConfigMapReference references the content of a profile in a ConfigMap.
+type ProfileConfigMapReference struct {
+	// Name is the name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Key is the key of the profile content in the ConfigMap.
+	Key string `json:"key"`
+}
+
+// MetadataBackup controls the backups of the lvm2 metadata of a volume group stored in the cluster.
+type MetadataBackup struct {
+	// StorageType is the kind of object the backups are stored in. The object is owned by the VolumeGroup
+	// and named after it with the suffix -metadata-backup.
+	// +kubebuilder:default=ConfigMap
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	StorageType MetadataBackupStorageType `json:"storageType,omitempty"`
+
+	// Interval is the interval in which a backup is taken even if the metadata did not change.
+	// If not specified, backups are only taken when the metadata changed.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Retention is the number of backups that are kept. Older backups are removed.
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	Retention int32 `json:"retention,omitempty"`
+}
+
+// MetadataBackupStatus reports the backups of the lvm2 metadata of a volume group stored in the cluster.
+type MetadataBackupStatus struct {
+	// Name is the name of the ConfigMap or Secret the backups are stored in.
+	Name string `json:"name"`
+
+	// Backups are the keys of the stored backups, oldest first.
+	// A backup is restored by setting the topolvm.io/restore-metadata-backup annotation to its key.
+	Backups []string `json:"backups,omitempty"`
+
+	// LastBackupTime is the time the last backup was taken.
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// SequenceNumber is the sequence number of the metadata in the last backup.
+	SequenceNumber int64 `json:"seqno,omitempty"`
+
+	// LastRestore is the key of the last restored backup.
+	LastRestore string `json:"lastRestore,omitempty"`
+
+	// LastRestoreTime is the time the last backup was restored.
+	LastRestoreTime *metav1.Time `json:"lastRestoreTime,omitempty"`
+}
+
+// SharedVolumeGroup configures a volume group shared between several nodes with lvmlockd.
+type SharedVolumeGroup struct {
+	// LockType is the lock manager used by lvmlockd for the volume group.
+	// +kubebuilder:validation:Enum=Sanlock;DLM
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="the lock type cannot be changed once set"
+	LockType LockType `json:"lockType"`
+
+	// NodeNames are the names of the nodes the volume group is used on in addition to VolumeGroupSpec.NodeName.
+	// When a node is removed from the list, the lockspace is stopped on that node.
+	// +listType=set
+	NodeNames []string `json:"nodeNames,omitempty"`
+}
+
+// NodeLockStatus is the state of the lockspace of a shared volume group on a node.
+type NodeLockStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+
+	// Started is true if the lockspace of the volume group is started in lvmlockd on the node.
+	Started bool `json:"started"`
+
+	// Message is the error of the last attempt to start or stop the lockspace on the node, if any.
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the time the lockspace was last started or stopped on the node.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// VolumeGroupImport identifies an exported volume group to import.
+type VolumeGroupImport struct {
+	// UUID is the UUID of the exported volume group, as reported in VolumeGroupStatus.UUID on the node it was exported from.
+	// +kubebuilder:validation:MinLength=1
+	UUID string `json:"uuid"`
+}
+
+// VolumeGroupDrift is a difference between the spec and the volume group on the node.
+type VolumeGroupDrift struct {
+	// Field is the field of the spec that differs from the volume group on the node, e.g. tags.
+	Field string `json:"field"`
+
+	// Desired is the value of the field in the spec.
+	Desired string `json:"desired,omitempty"`
+
+	// Actual is the value of the field on the node.
+	Actual string `json:"actual,omitempty"`
+
+	// Operation is the lvm2 operation that corrects the difference.
+	Operation string `json:"operation"`
+}
+
+// PlannedOperation is an lvm2 operation the controller would run to sync the volume group with the spec.
+type PlannedOperation struct {
+	// Field is the field of the spec that causes the operation, e.g. physicalVolumeSelector.
+	// It is empty for operations not caused by a single field, e.g. the creation of the volume group.
+	Field string `json:"field,omitempty"`
+
+	// Operation is the lvm2 operation that would be run.
+	Operation string `json:"operation"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -316,6 +1076,20 @@ type PVSelectorTerm struct {
 	// +optional
 	// +listType=atomic
 	MatchLSBLK []LSBLKSelectorRequirement `json:"matchLSBLK,omitempty"`
+
+	// MetadataIgnore controls whether the metadata areas of the physical volumes matching the term are ignored,
+	// corresponding to pvchange --metadataignore. Ignored metadata areas are not written to, but kept for later use.
+	// It should only be used with unmanaged VolumeGroupSpec.MetadataCopies, as lvm2 chooses the ignored
+	// metadata areas itself otherwise. If a physical volume matches multiple terms, the first term setting
+	// MetadataIgnore is used. If not specified, the metadata areas are not changed.
+	// +optional
+	MetadataIgnore *bool `json:"metadataIgnore,omitempty"`
+
+	// Tags are added to the physical volumes matching the term with pvchange --addtag, e.g. to mark fast devices
+	// as cache devices for VolumeGroupCache. Tags are never removed from physical volumes by the controller.
+	// +optional
+	// +listType=set
+	Tags []string `json:"tags,omitempty"`
 }
 
 // LSBLKSelectorRequirement is a selector that contains values, a key, and an operator
@@ -381,14 +1155,107 @@ const (
 // In this case, any LVs and dependent snapshots that were partly on the missing disks are removed completely,
 // including those parts on disks that are still present.
 // If LVs spanned several disks, including ones that are lost, salvaging some data first may be possible by activating LVs in partial mode.
+// If set to DeviceLossSynchronizationPolicyWaitForReturn, missing PVs are waited for to return, e.g. after a transient
+// path loss, and only removed as with DeviceLossWaitForReturn.FallbackPolicy once the grace period expired.
 type DeviceLossSynchronizationPolicy string
 
 const (
-	DeviceLossSynchronizationPolicyFail               DeviceLossSynchronizationPolicy = "Fail"        // See DeviceLossSynchronizationPolicy for more information.
-	DeviceLossSynchronizationPolicyRemoveMissing      DeviceLossSynchronizationPolicy = "Remove"      // See DeviceLossSynchronizationPolicy for more information.
-	DeviceLossSynchronizationPolicyForceRemoveMissing DeviceLossSynchronizationPolicy = "ForceRemove" // See DeviceLossSynchronizationPolicy for more information.
+	DeviceLossSynchronizationPolicyFail               DeviceLossSynchronizationPolicy = "Fail"          // See DeviceLossSynchronizationPolicy for more information.
+	DeviceLossSynchronizationPolicyRemoveMissing      DeviceLossSynchronizationPolicy = "Remove"        // See DeviceLossSynchronizationPolicy for more information.
+	DeviceLossSynchronizationPolicyForceRemoveMissing DeviceLossSynchronizationPolicy = "ForceRemove"   // See DeviceLossSynchronizationPolicy for more information.
+	DeviceLossSynchronizationPolicyWaitForReturn      DeviceLossSynchronizationPolicy = "WaitForReturn" // See DeviceLossSynchronizationPolicy for more information.
+)
+
+// DeviceLossWaitForReturn configures how long missing physical volumes are waited for to return.
+type DeviceLossWaitForReturn struct {
+	// GracePeriod is the duration a physical volume may be missing before it is removed from the volume group.
+	// The grace period is tracked per physical volume in VolumeGroupStatus.MissingPhysicalVolumes. As all missing
+	// physical volumes are removed at once, they are only removed once the grace period of each of them expired.
+	// +kubebuilder:default="5m"
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// FallbackPolicy is the policy used to remove missing physical volumes once the grace period expired.
+	// +kubebuilder:default=Remove
+	// +kubebuilder:validation:Enum=Remove;ForceRemove
+	FallbackPolicy DeviceLossSynchronizationPolicy `json:"fallbackPolicy,omitempty"`
+}
+
+// MissingPhysicalVolumeStatus reports a physical volume missing from the volume group.
+type MissingPhysicalVolumeStatus struct {
+	// UUID is the UUID of the missing physical volume.
+	UUID string `json:"uuid"`
+
+	// LastWritePath is the path of the device the physical volume was last written to.
+	LastWritePath string `json:"lastWritePath,omitempty"`
+
+	// MissingSince is the time the physical volume was first found missing.
+	MissingSince metav1.Time `json:"missingSince"`
+}
+
+// PhysicalVolumeReplacement replaces a missing physical volume with a new device.
+type PhysicalVolumeReplacement struct {
+	// MissingPhysicalVolume is the UUID of the missing physical volume, as reported in
+	// VolumeGroupStatus.MissingPhysicalVolumes.
+	// +kubebuilder:validation:MinLength=1
+	MissingPhysicalVolume string `json:"missingPhysicalVolume"`
+
+	// Device is the path of the device that replaces the missing physical volume, e.g. /dev/sdc.
+	// The device should also be matched by the PhysicalVolumeSelector, otherwise it is removed again after the replacement.
+	// With DevicePreparation, the device is prepared like any other device, e.g. its dm-crypt mapping replaces the
+	// missing physical volume if the volume group is encrypted.
+	// +kubebuilder:validation:MinLength=1
+	Device string `json:"device"`
+
+	// Force removes logical volumes that cannot be repaired, e.g. linear or striped logical volumes with extents on the
+	// missing physical volume, when the missing physical volume is removed from the volume group.
+	// Without Force, the replacement fails at the RemoveMissing step as long as such logical volumes exist.
+	// +optional
+	Force bool `json:"force,omitempty"`
+}
+
+// PhysicalVolumeReplacementStep is a step of the replacement of a missing physical volume.
+// See PhysicalVolumeReplacementStatus for more information.
+// +kubebuilder:validation:Enum=Extend;Repair;RemoveMissing;Completed
+type PhysicalVolumeReplacementStep string
+
+const (
+	PhysicalVolumeReplacementStepExtend        PhysicalVolumeReplacementStep = "Extend"        // See PhysicalVolumeReplacementStep for more information.
+	PhysicalVolumeReplacementStepRepair        PhysicalVolumeReplacementStep = "Repair"        // See PhysicalVolumeReplacementStep for more information.
+	PhysicalVolumeReplacementStepRemoveMissing PhysicalVolumeReplacementStep = "RemoveMissing" // See PhysicalVolumeReplacementStep for more information.
+	PhysicalVolumeReplacementStepCompleted     PhysicalVolumeReplacementStep = "Completed"     // See PhysicalVolumeReplacementStep for more information.
 )
 
+// PhysicalVolumeReplacementStatus is the progress of the replacement of a missing physical volume.
+// A replacement runs through the steps Extend (vgextend with the device), Repair (lvconvert --repair of the
+// RAID and mirror logical volumes onto the device) and RemoveMissing (vgreduce --removemissing) until it is Completed.
+// The steps run one at a time across all replacements. As vgreduce --removemissing removes all missing physical volumes,
+// RemoveMissing fails as long as any missing physical volume has no replacement or its replacement is not repaired yet.
+type PhysicalVolumeReplacementStatus struct {
+	// MissingPhysicalVolume is the UUID of the replaced physical volume.
+	MissingPhysicalVolume string `json:"missingPhysicalVolume"`
+
+	// Device is the path of the device that replaces the missing physical volume.
+	Device string `json:"device"`
+
+	// Step is the current step of the replacement.
+	Step PhysicalVolumeReplacementStep `json:"step"`
+
+	// Failed is true if the last attempt of the current step failed. The step is retried with the next sync.
+	Failed bool `json:"failed,omitempty"`
+
+	// Message describes the outcome of the last attempt of the current step.
+	Message string `json:"message,omitempty"`
+
+	// RepairedLogicalVolumes are the logical volumes that were repaired onto the device.
+	RepairedLogicalVolumes []string `json:"repairedLogicalVolumes,omitempty"`
+
+	// StartTime is the time the replacement was started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// LastTransitionTime is the time the replacement last moved to another step.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
 // DeviceRemovalVolumePolicy controls how the volume group will be synchronized when devices are removed from the desired set of physical volumes.
 // If set to DeviceRemovalVolumePolicyMoveAndReduce, all extents will be attempted to be moved to the remaining physical volumes before reducing.
 // This can fail if there are not sufficient extents available in the leftover devices.
@@ -403,3 +1270,74 @@ const (
 	DeviceRemovalVolumePolicyReduce        DeviceRemovalVolumePolicy = "Reduce"        // See DeviceRemovalVolumePolicy for more information.
 	DeviceRemovalVolumePolicyForceReduce   DeviceRemovalVolumePolicy = "ForceReduce"   // See DeviceRemovalVolumePolicy for more information.
 )
+
+// DriftPolicy controls how differences between the spec and the volume group on the node are handled.
+// If set to DriftPolicyCorrect, the volume group on the node is changed to match the spec.
+// If set to DriftPolicyReport, the differences are only reported in VolumeGroupStatus.Drift and
+// the Drifted condition, and the volume group on the node is not changed.
+type DriftPolicy string
+
+const (
+	DriftPolicyCorrect DriftPolicy = "Correct" // See DriftPolicy for more information.
+	DriftPolicyReport  DriftPolicy = "Report"  // See DriftPolicy for more information.
+)
+
+// Activation is the activation state of the logical volumes in a volume group.
+// ActivationActive and ActivationInactive can be requested in the spec,
+// ActivationPartiallyActive is only reported in the status if some, but not all logical volumes are active.
+type Activation string
+
+const (
+	ActivationActive          Activation = "Active"          // See Activation for more information.
+	ActivationInactive        Activation = "Inactive"        // See Activation for more information.
+	ActivationPartiallyActive Activation = "PartiallyActive" // See Activation for more information.
+)
+
+// ActivationMode controls how the logical volumes of a shared volume group are activated.
+type ActivationMode string
+
+const (
+	ActivationModeExclusive ActivationMode = "Exclusive" // See ActivationMode for more information.
+	ActivationModeShared    ActivationMode = "Shared"    // See ActivationMode for more information.
+)
+
+// LogicalVolumeActivationPolicy controls whether inactive logical volumes in a volume group are activated
+// if the activation is not managed with VolumeGroupSpec.Activation.
+// If set to LogicalVolumeActivationPolicyNone, inactive logical volumes are only reported.
+// If set to LogicalVolumeActivationPolicyOnStartup, inactive logical volumes are activated on the first sync after
+// the controller started, e.g. after a reboot of the node, but may be deactivated on the node afterwards.
+// If set to LogicalVolumeActivationPolicyAlways, inactive logical volumes are activated on every sync.
+type LogicalVolumeActivationPolicy string
+
+const (
+	LogicalVolumeActivationPolicyNone      LogicalVolumeActivationPolicy = "None"      // See LogicalVolumeActivationPolicy for more information.
+	LogicalVolumeActivationPolicyOnStartup LogicalVolumeActivationPolicy = "OnStartup" // See LogicalVolumeActivationPolicy for more information.
+	LogicalVolumeActivationPolicyAlways    LogicalVolumeActivationPolicy = "Always"    // See LogicalVolumeActivationPolicy for more information.
+)
+
+// LockType is the lock manager used by lvmlockd for a shared volume group.
+// Sanlock uses a lease area on the shared disks and requires no cluster infrastructure.
+// DLM uses the distributed lock manager of a corosync cluster.
+type LockType string
+
+const (
+	LockTypeSanlock LockType = "Sanlock" // See LockType for more information.
+	LockTypeDLM     LockType = "DLM"     // See LockType for more information.
+)
+
+// SystemIDSource is the source of the system ID of a volume group.
+type SystemIDSource string
+
+const (
+	SystemIDSourceNodeName SystemIDSource = "NodeName" // See SystemIDPolicy for more information.
+	SystemIDSourceHost     SystemIDSource = "Host"     // See SystemIDPolicy for more information.
+	SystemIDSourceValue    SystemIDSource = "Value"    // See SystemIDPolicy for more information.
+)
+
+// MetadataBackupStorageType is the kind of object backups of the lvm2 metadata are stored in.
+type MetadataBackupStorageType string
+
+const (
+	MetadataBackupStorageTypeConfigMap MetadataBackupStorageType = "ConfigMap" // See MetadataBackup for more information.
+	MetadataBackupStorageTypeSecret    MetadataBackupStorageType = "Secret"    // See MetadataBackup for more information.
+)
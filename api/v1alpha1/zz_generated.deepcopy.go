@@ -25,6 +25,144 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIStorageCapacityPublishing) DeepCopyInto(out *CSIStorageCapacityPublishing) {
+	*out = *in
+	if in.MatchParameters != nil {
+		in, out := &in.MatchParameters, &out.MatchParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIStorageCapacityPublishing.
+func (in *CSIStorageCapacityPublishing) DeepCopy() *CSIStorageCapacityPublishing {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIStorageCapacityPublishing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CachedLogicalVolumeStatus) DeepCopyInto(out *CachedLogicalVolumeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CachedLogicalVolumeStatus.
+func (in *CachedLogicalVolumeStatus) DeepCopy() *CachedLogicalVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CachedLogicalVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceEncryption) DeepCopyInto(out *DeviceEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceEncryption.
+func (in *DeviceEncryption) DeepCopy() *DeviceEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceHealth) DeepCopyInto(out *DeviceHealth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceHealth.
+func (in *DeviceHealth) DeepCopy() *DeviceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceLossWaitForReturn) DeepCopyInto(out *DeviceLossWaitForReturn) {
+	*out = *in
+	out.GracePeriod = in.GracePeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceLossWaitForReturn.
+func (in *DeviceLossWaitForReturn) DeepCopy() *DeviceLossWaitForReturn {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceLossWaitForReturn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevicePreparation) DeepCopyInto(out *DevicePreparation) {
+	*out = *in
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(DeviceEncryption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePreparation.
+func (in *DevicePreparation) DeepCopy() *DevicePreparation {
+	if in == nil {
+		return nil
+	}
+	out := new(DevicePreparation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptedDeviceStatus) DeepCopyInto(out *EncryptedDeviceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptedDeviceStatus.
+func (in *EncryptedDeviceStatus) DeepCopy() *EncryptedDeviceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptedDeviceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionStatus) DeepCopyInto(out *EncryptionStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]EncryptedDeviceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionStatus.
+func (in *EncryptionStatus) DeepCopy() *EncryptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LSBLKSelectorRequirement) DeepCopyInto(out *LSBLKSelectorRequirement) {
 	*out = *in
@@ -45,6 +183,136 @@ func (in *LSBLKSelectorRequirement) DeepCopy() *LSBLKSelectorRequirement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataBackup) DeepCopyInto(out *MetadataBackup) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataBackup.
+func (in *MetadataBackup) DeepCopy() *MetadataBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataBackupStatus) DeepCopyInto(out *MetadataBackupStatus) {
+	*out = *in
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestoreTime != nil {
+		in, out := &in.LastRestoreTime, &out.LastRestoreTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataBackupStatus.
+func (in *MetadataBackupStatus) DeepCopy() *MetadataBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataProfile) DeepCopyInto(out *MetadataProfile) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ProfileConfigMapReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataProfile.
+func (in *MetadataProfile) DeepCopy() *MetadataProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissingPhysicalVolumeStatus) DeepCopyInto(out *MissingPhysicalVolumeStatus) {
+	*out = *in
+	in.MissingSince.DeepCopyInto(&out.MissingSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissingPhysicalVolumeStatus.
+func (in *MissingPhysicalVolumeStatus) DeepCopy() *MissingPhysicalVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MissingPhysicalVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLockStatus) DeepCopyInto(out *NodeLockStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLockStatus.
+func (in *NodeLockStatus) DeepCopy() *NodeLockStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLockStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationTimeouts) DeepCopyInto(out *OperationTimeouts) {
+	*out = *in
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Mutation != nil {
+		in, out := &in.Mutation, &out.Mutation
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LongOperation != nil {
+		in, out := &in.LongOperation, &out.LongOperation
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationTimeouts.
+func (in *OperationTimeouts) DeepCopy() *OperationTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PVSelectorTerm) DeepCopyInto(out *PVSelectorTerm) {
 	*out = *in
@@ -55,6 +323,16 @@ func (in *PVSelectorTerm) DeepCopyInto(out *PVSelectorTerm) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MetadataIgnore != nil {
+		in, out := &in.MetadataIgnore, &out.MetadataIgnore
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVSelectorTerm.
@@ -67,6 +345,108 @@ func (in *PVSelectorTerm) DeepCopy() *PVSelectorTerm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalVolumeHealth) DeepCopyInto(out *PhysicalVolumeHealth) {
+	*out = *in
+	if in.SMARTPassed != nil {
+		in, out := &in.SMARTPassed, &out.SMARTPassed
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalVolumeHealth.
+func (in *PhysicalVolumeHealth) DeepCopy() *PhysicalVolumeHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalVolumeHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalVolumeMoveOptions) DeepCopyInto(out *PhysicalVolumeMoveOptions) {
+	*out = *in
+	if in.MaximumExtentsPerMove != nil {
+		in, out := &in.MaximumExtentsPerMove, &out.MaximumExtentsPerMove
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PauseBetweenMoves != nil {
+		in, out := &in.PauseBetweenMoves, &out.PauseBetweenMoves
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalVolumeMoveOptions.
+func (in *PhysicalVolumeMoveOptions) DeepCopy() *PhysicalVolumeMoveOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalVolumeMoveOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalVolumeMoveStatus) DeepCopyInto(out *PhysicalVolumeMoveStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.LastCompletionTime != nil {
+		in, out := &in.LastCompletionTime, &out.LastCompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalVolumeMoveStatus.
+func (in *PhysicalVolumeMoveStatus) DeepCopy() *PhysicalVolumeMoveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalVolumeMoveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalVolumeReplacement) DeepCopyInto(out *PhysicalVolumeReplacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalVolumeReplacement.
+func (in *PhysicalVolumeReplacement) DeepCopy() *PhysicalVolumeReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalVolumeReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalVolumeReplacementStatus) DeepCopyInto(out *PhysicalVolumeReplacementStatus) {
+	*out = *in
+	if in.RepairedLogicalVolumes != nil {
+		in, out := &in.RepairedLogicalVolumes, &out.RepairedLogicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalVolumeReplacementStatus.
+func (in *PhysicalVolumeReplacementStatus) DeepCopy() *PhysicalVolumeReplacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalVolumeReplacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in PhysicalVolumeSelector) DeepCopyInto(out *PhysicalVolumeSelector) {
 	{
@@ -96,6 +476,11 @@ func (in *PhysicalVolumeStatus) DeepCopyInto(out *PhysicalVolumeStatus) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(PhysicalVolumeHealth)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Size != nil {
 		in, out := &in.Size, &out.Size
 		x := (*in).DeepCopy()
@@ -143,6 +528,221 @@ func (in *PhysicalVolumeStatus) DeepCopy() *PhysicalVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedOperation) DeepCopyInto(out *PlannedOperation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedOperation.
+func (in *PlannedOperation) DeepCopy() *PlannedOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileConfigMapReference) DeepCopyInto(out *ProfileConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileConfigMapReference.
+func (in *ProfileConfigMapReference) DeepCopy() *ProfileConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedCapacity) DeepCopyInto(out *ReservedCapacity) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedCapacity.
+func (in *ReservedCapacity) DeepCopy() *ReservedCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeGroup) DeepCopyInto(out *SharedVolumeGroup) {
+	*out = *in
+	if in.NodeNames != nil {
+		in, out := &in.NodeNames, &out.NodeNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeGroup.
+func (in *SharedVolumeGroup) DeepCopy() *SharedVolumeGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemIDPolicy) DeepCopyInto(out *SystemIDPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemIDPolicy.
+func (in *SystemIDPolicy) DeepCopy() *SystemIDPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemIDPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPool) DeepCopyInto(out *ThinPool) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MetadataSize != nil {
+		in, out := &in.MetadataSize, &out.MetadataSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Autoextend != nil {
+		in, out := &in.Autoextend, &out.Autoextend
+		*out = new(ThinPoolAutoextend)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPool.
+func (in *ThinPool) DeepCopy() *ThinPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPoolAutoextend) DeepCopyInto(out *ThinPoolAutoextend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPoolAutoextend.
+func (in *ThinPoolAutoextend) DeepCopy() *ThinPoolAutoextend {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPoolAutoextend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPoolStatus) DeepCopyInto(out *ThinPoolStatus) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MetadataSize != nil {
+		in, out := &in.MetadataSize, &out.MetadataSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPoolStatus.
+func (in *ThinPoolStatus) DeepCopy() *ThinPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopoLVMDeviceClass) DeepCopyInto(out *TopoLVMDeviceClass) {
+	*out = *in
+	if in.SpareGB != nil {
+		in, out := &in.SpareGB, &out.SpareGB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ThinPool != nil {
+		in, out := &in.ThinPool, &out.ThinPool
+		*out = new(TopoLVMThinPool)
+		**out = **in
+	}
+	if in.LVCreateOptions != nil {
+		in, out := &in.LVCreateOptions, &out.LVCreateOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopoLVMDeviceClass.
+func (in *TopoLVMDeviceClass) DeepCopy() *TopoLVMDeviceClass {
+	if in == nil {
+		return nil
+	}
+	out := new(TopoLVMDeviceClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopoLVMThinPool) DeepCopyInto(out *TopoLVMThinPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopoLVMThinPool.
+func (in *TopoLVMThinPool) DeepCopy() *TopoLVMThinPool {
+	if in == nil {
+		return nil
+	}
+	out := new(TopoLVMThinPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeGroup) DeepCopyInto(out *VolumeGroup) {
 	*out = *in
@@ -170,6 +770,57 @@ func (in *VolumeGroup) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupCache) DeepCopyInto(out *VolumeGroupCache) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.LogicalVolumes != nil {
+		in, out := &in.LogicalVolumes, &out.LogicalVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupCache.
+func (in *VolumeGroupCache) DeepCopy() *VolumeGroupCache {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupDrift) DeepCopyInto(out *VolumeGroupDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupDrift.
+func (in *VolumeGroupDrift) DeepCopy() *VolumeGroupDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupImport) DeepCopyInto(out *VolumeGroupImport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupImport.
+func (in *VolumeGroupImport) DeepCopy() *VolumeGroupImport {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeGroupList) DeepCopyInto(out *VolumeGroupList) {
 	*out = *in
@@ -217,6 +868,11 @@ func (in *VolumeGroupSpec) DeepCopyInto(out *VolumeGroupSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DevicePreparation != nil {
+		in, out := &in.DevicePreparation, &out.DevicePreparation
+		*out = new(DevicePreparation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]string, len(*in))
@@ -242,6 +898,16 @@ func (in *VolumeGroupSpec) DeepCopyInto(out *VolumeGroupSpec) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.MetadataCopies != nil {
+		in, out := &in.MetadataCopies, &out.MetadataCopies
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetadataProfile != nil {
+		in, out := &in.MetadataProfile, &out.MetadataProfile
+		*out = new(MetadataProfile)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AllocationPolicy != nil {
 		in, out := &in.AllocationPolicy, &out.AllocationPolicy
 		*out = new(AllocationPolicy)
@@ -277,6 +943,93 @@ func (in *VolumeGroupSpec) DeepCopyInto(out *VolumeGroupSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DeviceLossWaitForReturn != nil {
+		in, out := &in.DeviceLossWaitForReturn, &out.DeviceLossWaitForReturn
+		*out = new(DeviceLossWaitForReturn)
+		**out = **in
+	}
+	if in.PhysicalVolumeReplacements != nil {
+		in, out := &in.PhysicalVolumeReplacements, &out.PhysicalVolumeReplacements
+		*out = make([]PhysicalVolumeReplacement, len(*in))
+		copy(*out, *in)
+	}
+	if in.PhysicalVolumeMove != nil {
+		in, out := &in.PhysicalVolumeMove, &out.PhysicalVolumeMove
+		*out = new(PhysicalVolumeMoveOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(OperationTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Activation != nil {
+		in, out := &in.Activation, &out.Activation
+		*out = new(Activation)
+		**out = **in
+	}
+	if in.ActivationMode != nil {
+		in, out := &in.ActivationMode, &out.ActivationMode
+		*out = new(ActivationMode)
+		**out = **in
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(VolumeGroupImport)
+		**out = **in
+	}
+	if in.Shared != nil {
+		in, out := &in.Shared, &out.Shared
+		*out = new(SharedVolumeGroup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SystemIDPolicy != nil {
+		in, out := &in.SystemIDPolicy, &out.SystemIDPolicy
+		*out = new(SystemIDPolicy)
+		**out = **in
+	}
+	if in.MetadataBackup != nil {
+		in, out := &in.MetadataBackup, &out.MetadataBackup
+		*out = new(MetadataBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ThinPools != nil {
+		in, out := &in.ThinPools, &out.ThinPools
+		*out = make([]ThinPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReservedCapacity != nil {
+		in, out := &in.ReservedCapacity, &out.ReservedCapacity
+		*out = new(ReservedCapacity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSIStorageCapacity != nil {
+		in, out := &in.CSIStorageCapacity, &out.CSIStorageCapacity
+		*out = new(CSIStorageCapacityPublishing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(VolumeGroupCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceHealth != nil {
+		in, out := &in.DeviceHealth, &out.DeviceHealth
+		*out = new(DeviceHealth)
+		**out = **in
+	}
+	if in.TopoLVM != nil {
+		in, out := &in.TopoLVM, &out.TopoLVM
+		*out = new(TopoLVMDeviceClass)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupSpec.
@@ -319,6 +1072,71 @@ func (in *VolumeGroupStatus) DeepCopyInto(out *VolumeGroupStatus) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MissingPhysicalVolumes != nil {
+		in, out := &in.MissingPhysicalVolumes, &out.MissingPhysicalVolumes
+		*out = make([]MissingPhysicalVolumeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PhysicalVolumeMoves != nil {
+		in, out := &in.PhysicalVolumeMoves, &out.PhysicalVolumeMoves
+		*out = make([]PhysicalVolumeMoveStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PhysicalVolumeReplacements != nil {
+		in, out := &in.PhysicalVolumeReplacements, &out.PhysicalVolumeReplacements
+		*out = make([]PhysicalVolumeReplacementStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Locks != nil {
+		in, out := &in.Locks, &out.Locks
+		*out = make([]NodeLockStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetadataBackup != nil {
+		in, out := &in.MetadataBackup, &out.MetadataBackup
+		*out = new(MetadataBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(EncryptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = make([]CachedLogicalVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ThinPools != nil {
+		in, out := &in.ThinPools, &out.ThinPools
+		*out = make([]ThinPoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]VolumeGroupDrift, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlannedOperations != nil {
+		in, out := &in.PlannedOperations, &out.PlannedOperations
+		*out = make([]PlannedOperation, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -18,6 +18,7 @@ package main
 
 import (
 	"crypto/tls"
+	"errors"
 	"flag"
 	"log/slog"
 	"os"
@@ -30,10 +31,12 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -42,6 +45,8 @@ import (
 
 	topolvmv1alpha1 "github.com/topolvm/topovgm/api/v1alpha1"
 	"github.com/topolvm/topovgm/internal/controller"
+	"github.com/topolvm/topovgm/internal/health"
+	"github.com/topolvm/topovgm/internal/hostevents"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,22 +64,65 @@ func init() {
 
 func main() {
 	var metricsAddr string
-	var enableLeaderElection bool
+	var enableNodeLease bool
 	var probeAddr string
 	var secureMetrics bool
 	var volumeGroupSyncInterval time.Duration
+	var volumeGroupSyncJitter float64
+	var lvmBackupDir string
+	var renderLVMDConfig bool
+	var nodeCapacityAnnotations bool
+	var nodeCapacityExtendedResources bool
+	var sysfsRoot string
+	var smartctl string
+	var timeouts controller.Timeouts
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
-		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableNodeLease, "node-lease", true,
+		"If set, the controller acquires a Lease named after the node before reconciling volume groups. "+
+			"This ensures only one controller manager per node runs lvm2 commands at a time, e.g. during a rolling update. "+
+			"The Lease is created in the namespace given by the POD_NAMESPACE environment variable.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.DurationVar(&volumeGroupSyncInterval, "volume-group-sync-interval", 10*time.Second,
 		"If set, the controller will sync volume groups this often, picking up changes on the host or made externally. "+
 			"If set to a negative value or 0, the controller will only sync volume groups when they change through the controller.")
+	flag.Float64Var(&volumeGroupSyncJitter, "volume-group-sync-jitter", 0.1,
+		"The maximum factor by which the sync interval of a volume group is randomly extended, "+
+			"so that syncs of many volume groups do not happen at the same time. If set to 0, no jitter is applied.")
+	flag.StringVar(&lvmBackupDir, "watch-lvm-backup-dir", "",
+		"If set, the lvm2 metadata backup directory (usually "+hostevents.DefaultLVMBackupDir+") is watched "+
+			"and volume groups are synced as soon as their metadata changes on the node. "+
+			"Volume groups covered by the watch are not synced periodically unless they specify a sync interval. "+
+			"When running in a container with the host PID namespace, the directory of the host is available below /proc/1/root.")
+	flag.BoolVar(&renderLVMDConfig, "render-lvmd-config", false,
+		"If set, the device classes of the volume groups on the node that opt in through .spec.topolvm are rendered "+
+			"into the lvmd configuration of TopoLVM, stored under the key "+controller.LVMDConfigKey+" of the ConfigMap "+
+			"topovgm-lvmd-<node> in the namespace given by the POD_NAMESPACE environment variable.")
+	flag.BoolVar(&nodeCapacityAnnotations, "node-capacity-annotations", false,
+		"If set, the allocatable capacity of each volume group is published on its Node in the annotation "+
+			controller.NodeCapacityAnnotationPrefix+"<name of the volume group on the node>. "+
+			"Names that are no valid annotation key are shortened and suffixed with a hash of the name.")
+	flag.BoolVar(&nodeCapacityExtendedResources, "node-capacity-extended-resources", false,
+		"If set, the allocatable capacity of each volume group is published on its Node as the extended resource "+
+			controller.NodeCapacityResourcePrefix+"<name of the volume group on the node>. "+
+			"Names that are no valid resource name are shortened and suffixed with a hash of the name.")
+	flag.StringVar(&sysfsRoot, "sysfs-root", health.DefaultSysfsRoot,
+		"The mount point of the sysfs of the node, which is read to monitor the health of devices.")
+	flag.StringVar(&smartctl, "smartctl", "/usr/sbin/smartctl",
+		"The path of smartctl on the node, which is used to monitor the SMART health of devices if installed. "+
+			"If empty, SMART is not checked.")
+	flag.DurationVar(&timeouts.Discovery, "discovery-timeout", 10*time.Second,
+		"The timeout for reading the state of a volume group and its devices from the node. "+
+			"Can be overridden per VolumeGroup. If set to a negative value or 0, discovery does not time out.")
+	flag.DurationVar(&timeouts.Mutation, "mutation-timeout", 30*time.Second,
+		"The timeout for a single operation changing a volume group without touching the data on its devices, e.g. vgchange. "+
+			"Can be overridden per VolumeGroup. If set to a negative value or 0, mutations do not time out.")
+	flag.DurationVar(&timeouts.LongOperation, "long-operation-timeout", 5*time.Minute,
+		"The timeout for a single operation that initializes or wipes devices, e.g. vgcreate, vgextend or vgremove. "+
+			"Can be overridden per VolumeGroup. If set to a negative value or 0, long operations do not time out.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -108,26 +156,68 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	nodeName := os.Getenv("NODE_NAME")
+	if nodeName == "" {
+		setupLog.Error(errors.New("NODE_NAME is not set"), "unable to determine the node to manage volume groups on")
+		os.Exit(1)
+	}
+
+	// The controller runs once per node, so there is no cluster-wide leader election.
+	// Instead, a Lease per node gates the reconciliation of the volume groups on that node.
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                        scheme,
 		Metrics:                       metricsServerOptions,
 		WebhookServer:                 webhookServer,
 		HealthProbeBindAddress:        probeAddr,
-		LeaderElection:                enableLeaderElection,
-		LeaderElectionID:              "c43d834a.io",
+		LeaderElection:                enableNodeLease,
+		LeaderElectionID:              nodeLeaseName(nodeName),
+		LeaderElectionNamespace:       os.Getenv("POD_NAMESPACE"),
 		LeaderElectionReleaseOnCancel: true,
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// Metadata backups are only read on restore, and ConfigMaps and Secrets are only granted per namespace,
+				// so they are read directly instead of being cached on every node. Likewise, each node only reads its own Node.
+				DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}, &corev1.Node{}},
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	var lvmdConfigNamespace string
+	if renderLVMDConfig {
+		if lvmdConfigNamespace = os.Getenv("POD_NAMESPACE"); lvmdConfigNamespace == "" {
+			setupLog.Error(errors.New("POD_NAMESPACE is not set"), "unable to determine the namespace of the lvmd configuration")
+			os.Exit(1)
+		}
+	}
+
+	var hostEvents controller.HostEventSource
+	if lvmBackupDir != "" {
+		watcher := hostevents.NewMetadataWatcher(lvmBackupDir)
+		if err := mgr.Add(watcher); err != nil {
+			setupLog.Error(err, "unable to set up lvm2 metadata backup watch")
+			os.Exit(1)
+		}
+		hostEvents = watcher
+	}
+
 	if err = (&controller.VolumeGroupReconciler{
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
-		NodeName:     os.Getenv("NODE_NAME"),
+		NodeName:     nodeName,
 		LVM:          lvm2go.NewClient(),
 		SyncInterval: volumeGroupSyncInterval,
+		SyncJitter:   volumeGroupSyncJitter,
+		HostEvents:   hostEvents,
+		Timeouts:     timeouts,
+
+		LVMDConfigNamespace:           lvmdConfigNamespace,
+		NodeCapacityAnnotations:       nodeCapacityAnnotations,
+		NodeCapacityExtendedResources: nodeCapacityExtendedResources,
+		Health:                        health.Monitor{SysfsRoot: sysfsRoot, Smartctl: smartctl},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VolumeGroup")
 		os.Exit(1)
@@ -149,3 +239,8 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// nodeLeaseName returns the name of the Lease gating the reconciliation of volume groups on the node.
+func nodeLeaseName(nodeName string) string {
+	return "topovgm-" + nodeName
+}
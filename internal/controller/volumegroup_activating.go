@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeLogicalVolumesActivating is a condition type that indicates whether inactive logical volumes
+	// in the volume group are being activated.
+	ConditionTypeLogicalVolumesActivating = "LogicalVolumesActivating"
+	ReasonActivatingLogicalVolumes        = "ActivatingLogicalVolumes"
+	ReasonLogicalVolumesActivated         = "LogicalVolumesActivated"
+	ReasonLogicalVolumeActivationFailed   = "LogicalVolumeActivationFailed"
+	MessageLogicalVolumesActivated        = "The logical volumes in the volume group were activated."
+)
+
+var LogicalVolumesActivating = metav1.Condition{
+	Type:    ConditionTypeLogicalVolumesActivating,
+	Status:  metav1.ConditionFalse,
+	Reason:  ReasonLogicalVolumesActivated,
+	Message: MessageLogicalVolumesActivated,
+}
+
+func SetLogicalVolumesActivating(conditions *[]metav1.Condition, generation int64, policy string) {
+	condition := *LogicalVolumesActivating.DeepCopy()
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonActivatingLogicalVolumes
+	condition.Message = fmt.Sprintf("Inactive logical volumes in the volume group are being activated due to %s.", policy)
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetLogicalVolumesActivated(conditions *[]metav1.Condition, generation int64) {
+	condition := *LogicalVolumesActivating.DeepCopy()
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetLogicalVolumeActivationFailed(conditions *[]metav1.Condition, generation int64, err error) {
+	condition := *LogicalVolumesActivating.DeepCopy()
+	condition.Reason = ReasonLogicalVolumeActivationFailed
+	condition.Message = fmt.Sprintf("logical volume activation failed: %s", err.Error())
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jakobmoellerdev/lvm2go"
@@ -27,9 +29,14 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmerr"
+	"github.com/topolvm/topovgm/internal/utils"
 )
 
 const (
@@ -43,13 +50,40 @@ type VolumeGroupReconciler struct {
 	LVM          lvm2go.Client
 	NodeName     string
 	SyncInterval time.Duration
+	SyncJitter   float64
+	HostEvents   HostEventSource
+	Timeouts     Timeouts
+
+	// LVMDConfigNamespace is the namespace the lvmd configuration of the node is rendered into.
+	// If empty, no lvmd configuration is rendered.
+	LVMDConfigNamespace string
+	// NodeCapacityAnnotations publishes the allocatable capacity of the volume groups in annotations of the Node.
+	NodeCapacityAnnotations bool
+	// NodeCapacityExtendedResources publishes the allocatable capacity of the volume groups as extended resources of the Node.
+	NodeCapacityExtendedResources bool
+	// Health reads the health signals of the devices of physical volumes. If nil, devices are not monitored.
+	Health HealthMonitor
+
+	// movesResumed is set once interrupted physical volume moves have been resumed after startup.
+	movesResumed atomic.Bool
+	// startupActivations contains the UIDs of VolumeGroups whose logical volumes were found active or activated
+	// since startup due to LogicalVolumeActivationPolicyOnStartup.
+	startupActivations sync.Map
+	// encryptionOpened contains the UIDs of VolumeGroups whose encrypted devices were opened since startup.
+	encryptionOpened sync.Map
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VolumeGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.VolumeGroup{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.VolumeGroup{})
+	if r.HostEvents != nil {
+		b = b.WatchesRawSource(source.Channel(
+			r.HostEvents.Events(),
+			handler.TypedEnqueueRequestsFromMapFunc(r.volumeGroupsForHostEvent),
+		))
+	}
+	return b.Complete(r)
 }
 
 // +kubebuilder:rbac:groups=topolvm.io,resources=volumegroups,verbs=get;list;watch;create;update;patch;delete
@@ -68,6 +102,9 @@ func (r *VolumeGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if r.NodeName != vg.Spec.NodeName {
+		if vg.Spec.Shared != nil || getLockStatus(&vg.Status, r.NodeName) != nil {
+			return r.reconcileSharedNode(ctx, vg)
+		}
 		logger.V(1).Info("skipping VolumeGroup due to mismatched .spec.nodeName",
 			"expected", vg.Spec.NodeName,
 			"actual", r.NodeName,
@@ -87,42 +124,105 @@ func (r *VolumeGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if force {
 			logger.V(1).Info("force removal of volume group from host due to passed grace period")
 		}
-		if err := r.LVM.VGRemove(ctx, name, lvm2go.Force(force)); err != nil {
+		if nodes := nodesWithStartedLock(&vg.Status, r.NodeName); len(nodes) > 0 && !force {
+			logger.Info("waiting for lockspace of shared volume group to be stopped on other nodes", "nodes", nodes)
+			return ctrl.Result{RequeueAfter: SharedLockStopInterval}, nil
+		}
+		if vg.Spec.Exported && vg.Status.Exported {
+			logger.Info("volume group is exported, detaching it from the VolumeGroup without removing it from host")
+		} else if err := r.withTimeout(ctx, vg, OperationLong, "vgremove", func(ctx context.Context) error {
+			return r.LVM.VGRemove(ctx, name, lvm2go.Force(force))
+		}); err != nil {
 			if lvm2go.IsLVMErrNotFound(err) {
 				logger.V(1).Info("volume group not found on host, removing finalizer")
 			} else {
-				return ctrl.Result{}, fmt.Errorf("failed to remove volume group: %w", err)
+				return ctrl.Result{}, classifyError(ctx, fmt.Errorf("failed to remove volume group: %w", err))
+			}
+		}
+		if controllerutil.ContainsFinalizer(vg, VolumeGroupFinalizer) {
+			if err := r.closeEncryptedDevices(ctx, vg); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to close encrypted devices: %w", err)
+			}
+			if err := r.publishNodeCapacity(ctx, vg, ""); err != nil {
+				return ctrl.Result{}, err
 			}
 		}
 		if updated := controllerutil.RemoveFinalizer(vg, VolumeGroupFinalizer); updated {
-			return ctrl.Result{}, r.Update(ctx, vg)
+			if err := r.Update(ctx, vg); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, r.renderLVMDConfig(ctx)
 		}
 		logger.V(1).Info("volume group has been removed and is now waiting for other finalizers to be removed")
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	if key, ok := vg.GetAnnotations()[RestoreMetadataBackupAnnotation]; ok {
+		return r.restoreMetadataBackup(ctx, vg, key)
+	}
+
+	if IsSyncedOnHostFailedTerminally(vg.Status.Conditions, vg.GetGeneration()) {
+		logger.V(1).Info("skipping volume group as its last sync failed terminally, waiting for a spec change")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.openEncryptedDevices(ctx, vg); errors.Is(err, ErrEncryptedDevicesClosed) {
+		logger.V(1).Info("encrypted devices are closed, not opening them in plan mode or with drift policy report")
+		return ctrl.Result{RequeueAfter: r.syncInterval(vg)}, r.Client.Status().Update(ctx, vg)
+	} else if err != nil {
+		return ctrl.Result{}, classifyError(ctx, fmt.Errorf("failed to open encrypted devices: %w", err))
+	}
+
 	logger.V(1).Info("syncing volume group with host, starting host discovery")
 	start := time.Now()
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-	lvm, err := r.LVM.VG(ctx, name, lvm2go.UnitBytes)
+	var lvm *lvm2go.VolumeGroup
+	err := r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		lvm, err = r.LVM.VG(ctx, name, lvm2go.UnitBytes)
+		return
+	})
 
 	logger.V(1).Info("host discovery completed", "duration", time.Since(start))
 
 	if errors.Is(err, lvm2go.ErrVolumeGroupNotFound) {
-		err = r.initializeVG(ctx, vg)
+		takenOver := false
+		if vg.Spec.Import != nil {
+			err = r.importVG(ctx, vg)
+		} else if vg.Spec.SystemIDPolicy != nil && vg.Spec.SystemIDPolicy.TakeoverFrom != "" {
+			takenOver, err = r.takeoverVG(ctx, vg)
+		}
+		if vg.Spec.Import == nil && !takenOver && err == nil {
+			err = r.initializeVG(ctx, vg)
+		}
 	}
 
 	if err != nil {
-		return ctrl.Result{}, errors.Join(err, r.Client.Status().Update(ctx, vg))
+		return ctrl.Result{}, classifyError(ctx, errors.Join(err, r.Client.Status().Update(ctx, vg)))
 	}
 
 	if updated := controllerutil.AddFinalizer(vg, VolumeGroupFinalizer); updated {
 		return ctrl.Result{Requeue: true}, r.Update(ctx, vg)
 	}
 
-	if err = r.sync(ctx, vg, lvm); err != nil {
+	if lvm == nil && isPlanOnly(vg) {
+		logger.V(1).Info("volume group creation, import or takeover planned, not changing host")
+		return ctrl.Result{RequeueAfter: r.syncInterval(vg)}, r.Client.Status().Update(ctx, vg)
+	}
+
+	if lvm == nil {
+		logger.V(1).Info("volume group was created, imported or taken over on host, requeue for discovery")
+		return ctrl.Result{Requeue: true}, r.Client.Status().Update(ctx, vg)
+	}
+
+	previousName := vg.Status.Name
+	requeueAfter := r.syncInterval(vg)
+	if err = r.sync(ctx, vg, lvm); errors.Is(err, ErrPhysicalVolumeMoveInProgress) {
+		logger.V(1).Info("physical volume move in progress, refreshing progress periodically")
+		requeueAfter, err = PhysicalVolumeMoveProgressInterval, nil
+	} else if errors.Is(err, ErrWaitingForDeviceReturn) {
+		logger.V(1).Info("waiting for missing physical volumes to return, checking periodically")
+		requeueAfter, err = deviceReturnRequeueAfter(vg, requeueAfter, time.Now()), nil
+	} else if err != nil {
 		err = fmt.Errorf("failed to sync volume group with lvm2: %w", err)
 	}
 
@@ -132,11 +232,31 @@ func (r *VolumeGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		logger.V(1).Info("status refreshed successfully")
 	}
 
+	if backupErr := r.backupMetadata(ctx, vg, lvm); backupErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to back up metadata of volume group: %w", backupErr))
+	}
+	// Periodic backups are also taken if the volume group is not synced periodically, e.g. due to host events.
+	if next := nextMetadataBackup(vg, time.Now()); next > 0 && (requeueAfter <= 0 || next < requeueAfter) {
+		requeueAfter = next
+	}
+
 	if err := errors.Join(err, r.Client.Status().Update(ctx, vg)); err != nil {
+		return ctrl.Result{}, classifyError(ctx, err)
+	}
+
+	if err := r.renderLVMDConfig(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.publishNodeCapacity(ctx, vg, previousName); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.publishStorageCapacity(ctx, vg); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: r.SyncInterval}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *VolumeGroupReconciler) initializeVG(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
@@ -146,14 +266,57 @@ func (r *VolumeGroupReconciler) initializeVG(ctx context.Context, vg *v1alpha1.V
 		log.FromContext(ctx).Info("finished creating volume group on host", "duration", time.Since(start))
 	}()
 
-	opts, err := convertToVGCreateOptions(ctx, vg)
+	var opts *lvm2go.VGCreateOptions
+	err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		opts, err = convertToVGCreateOptions(ctx, vg)
+		return
+	})
 	if err != nil {
-		return fmt.Errorf("failed to convert VolumeGroup to VGCreateOptions: %w", err)
+		err = fmt.Errorf("failed to convert VolumeGroup to VGCreateOptions: %w", err)
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
 	}
 
-	if err = r.LVM.VGCreate(ctx, opts); err != nil {
+	var prepare []drift
+	if opts.PhysicalVolumeNames, prepare, err = r.prepareEncryptedDevices(ctx, vg, opts.PhysicalVolumeNames); err != nil {
 		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
 	}
 
+	if isPlanOnly(vg) {
+		vg.Status.PlannedOperations = append(planDrift(utils.Map(prepare, func(d drift) v1alpha1.VolumeGroupDrift {
+			return d.VolumeGroupDrift
+		})), planCreation(opts))
+		SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(vg.Status.PlannedOperations))
+		return nil
+	}
+
+	for _, d := range prepare {
+		log.FromContext(ctx).Info("preparing device", "operation", d.Operation)
+		if err = d.correct(ctx); err != nil {
+			SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+			return err
+		}
+	}
+
+	if err = r.withTimeout(ctx, vg, OperationLong, "vgcreate", func(ctx context.Context) error {
+		return r.LVM.VGCreate(ctx, opts)
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+	}
+
+	return err
+}
+
+// classifyError decides how an error is retried based on its lvmerr.Class.
+// Terminal errors are not retried until the VolumeGroup changes, all other errors are retried with backoff.
+func classifyError(ctx context.Context, err error) error {
+	switch class := lvmerr.Classify(err); class {
+	case lvmerr.Terminal:
+		log.FromContext(ctx).Error(err, "terminal error, not retrying until the spec changes")
+		return reconcile.TerminalError(err)
+	case lvmerr.Transient:
+		log.FromContext(ctx).V(1).Info("transient error, retrying with backoff", "error", err.Error())
+	}
 	return err
 }
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// diffActivation calculates the difference between the desired and the actual activation of the logical volumes
+// in the volume group. Without Activation in the spec, the desired activation is derived from the
+// LogicalVolumeActivationPolicy, which only ever activates logical volumes.
+func (r *VolumeGroupReconciler) diffActivation(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	desired, byPolicy := r.desiredActivation(vg)
+	if desired == "" {
+		return nil, nil
+	}
+
+	actual, err := r.activation(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get activation to sync spec: %w", err)
+	}
+
+	if actual == "" || actual == desired {
+		if byPolicy {
+			r.startupActivations.Store(vg.GetUID(), struct{}{})
+		}
+		return nil, nil
+	}
+
+	activation := convertToActivation(desired, vg.Spec.ActivationMode)
+	operation := fmt.Sprintf("vgchange %s -a%s", lvm.Name, activation)
+	field := "activation"
+	if byPolicy {
+		field = "logicalVolumeActivationPolicy"
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     field,
+			Desired:   string(desired),
+			Actual:    string(actual),
+			Operation: operation,
+		},
+		correct: func(ctx context.Context) error {
+			if !byPolicy {
+				return r.withTimeout(ctx, vg, OperationMutation, "vgchange -a", func(ctx context.Context) error {
+					return lvmcmd.ChangeActivation(ctx, string(lvm.Name), activation)
+				})
+			}
+			return r.activateByPolicy(ctx, vg, lvm, activation)
+		},
+	}}, nil
+}
+
+// activateByPolicy activates the logical volumes in the volume group due to the LogicalVolumeActivationPolicy.
+// As activation can take a while with many logical volumes, the progress is surfaced in a condition before activating.
+func (r *VolumeGroupReconciler) activateByPolicy(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	activation lvmcmd.Activation,
+) error {
+	log.FromContext(ctx).Info("activating inactive logical volumes", "policy", vg.Spec.LogicalVolumeActivationPolicy)
+	SetLogicalVolumesActivating(&vg.Status.Conditions, vg.GetGeneration(), string(vg.Spec.LogicalVolumeActivationPolicy))
+	if err := r.Client.Status().Update(ctx, vg); err != nil {
+		return fmt.Errorf("could not report activation of logical volumes: %w", err)
+	}
+
+	if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange -a", func(ctx context.Context) error {
+		return lvmcmd.ChangeActivation(ctx, string(lvm.Name), activation)
+	}); err != nil {
+		SetLogicalVolumeActivationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
+	}
+
+	SetLogicalVolumesActivated(&vg.Status.Conditions, vg.GetGeneration())
+	r.startupActivations.Store(vg.GetUID(), struct{}{})
+	return nil
+}
+
+// desiredActivation returns the desired activation of the logical volumes in the volume group and whether it
+// is derived from the LogicalVolumeActivationPolicy. It returns an empty activation if it is not managed.
+func (r *VolumeGroupReconciler) desiredActivation(vg *v1alpha1.VolumeGroup) (v1alpha1.Activation, bool) {
+	if vg.Spec.Activation != nil {
+		return *vg.Spec.Activation, false
+	}
+
+	switch vg.Spec.LogicalVolumeActivationPolicy {
+	case v1alpha1.LogicalVolumeActivationPolicyAlways:
+		return v1alpha1.ActivationActive, true
+	case v1alpha1.LogicalVolumeActivationPolicyOnStartup:
+		if _, activated := r.startupActivations.Load(vg.GetUID()); !activated {
+			return v1alpha1.ActivationActive, true
+		}
+	}
+	return "", false
+}
+
+// activation returns the activation state of the logical volumes in the volume group,
+// or an empty activation if the volume group has no logical volumes.
+func (r *VolumeGroupReconciler) activation(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (activation v1alpha1.Activation, err error) {
+	var total, active int
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvs", func(ctx context.Context) (err error) {
+		total, active, err = lvmcmd.LogicalVolumeActivation(ctx, string(lvm.Name))
+		return
+	}); err != nil {
+		return "", err
+	}
+
+	switch {
+	case total == 0:
+		return "", nil
+	case active == total:
+		return v1alpha1.ActivationActive, nil
+	case active == 0:
+		return v1alpha1.ActivationInactive, nil
+	default:
+		return v1alpha1.ActivationPartiallyActive, nil
+	}
+}
+
+func convertToActivation(activation v1alpha1.Activation, mode *v1alpha1.ActivationMode) lvmcmd.Activation {
+	if activation == v1alpha1.ActivationInactive {
+		return lvmcmd.Deactivate
+	}
+	if mode == nil {
+		return lvmcmd.Activate
+	}
+	switch *mode {
+	case v1alpha1.ActivationModeExclusive:
+		return lvmcmd.ActivateExclusively
+	case v1alpha1.ActivationModeShared:
+		return lvmcmd.ActivateShared
+	}
+	return lvmcmd.Activate
+}
@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RestoreMetadataBackupAnnotation restores the metadata of the volume group from the backup with the key in the annotation
+// using vgcfgrestore. The annotation is removed once the backup was restored.
+const RestoreMetadataBackupAnnotation = "topolvm.io/restore-metadata-backup"
+
+// metadataBackupTimeFormat is the format of the time in the key of a backup, which keeps the keys sorted by time.
+const metadataBackupTimeFormat = "20060102T150405Z"
+
+// ConfigMaps and Secrets are only granted in the namespace of the controller, access to them in the namespaces of
+// VolumeGroups has to be granted with a Role per namespace, see the README.
+// +kubebuilder:rbac:groups="",namespace=system,resources=configmaps;secrets,verbs=get;create;update;patch
+
+// backupMetadata stores a backup of the metadata of the volume group in the cluster if the metadata changed since
+// the last backup or the backup interval passed.
+func (r *VolumeGroupReconciler) backupMetadata(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) error {
+	opts := vg.Spec.MetadataBackup
+	if opts == nil {
+		return nil
+	}
+	name := string(lvm.Name)
+
+	var seqno int64
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		seqno, err = lvmcmd.SequenceNumber(ctx, name)
+		return
+	}); err != nil {
+		return err
+	}
+
+	status := vg.Status.MetadataBackup
+	if status != nil && status.Name == metadataBackupName(vg) && status.SequenceNumber == seqno &&
+		(opts.Interval == nil || status.LastBackupTime == nil || time.Since(status.LastBackupTime.Time) < opts.Interval.Duration) {
+		return nil
+	}
+
+	var backup []byte
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "vgcfgbackup", func(ctx context.Context) (err error) {
+		backup, err = lvmcmd.Backup(ctx, name)
+		return
+	}); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	key := fmt.Sprintf("%s-%d.vg", now.UTC().Format(metadataBackupTimeFormat), seqno)
+	keys, err := r.storeMetadataBackup(ctx, vg, key, backup)
+	if err != nil {
+		return fmt.Errorf("could not store metadata backup: %w", err)
+	}
+	log.FromContext(ctx).V(1).Info("stored metadata backup", "key", key, "seqno", seqno)
+
+	if status == nil {
+		status = &v1alpha1.MetadataBackupStatus{}
+		vg.Status.MetadataBackup = status
+	}
+	status.Name = metadataBackupName(vg)
+	status.Backups = keys
+	status.LastBackupTime = &now
+	status.SequenceNumber = seqno
+	return nil
+}
+
+// nextMetadataBackup returns the time until the next periodic backup of the metadata is due,
+// or 0 if no backups are taken periodically.
+func nextMetadataBackup(vg *v1alpha1.VolumeGroup, now time.Time) time.Duration {
+	opts, status := vg.Spec.MetadataBackup, vg.Status.MetadataBackup
+	if opts == nil || opts.Interval == nil || opts.Interval.Duration <= 0 || status == nil || status.LastBackupTime == nil {
+		return 0
+	}
+	return max(status.LastBackupTime.Add(opts.Interval.Duration).Sub(now), time.Second)
+}
+
+// storeMetadataBackup stores the backup with the key in the object owned by the VolumeGroup and removes
+// the oldest backups exceeding the retention. It returns the keys of all stored backups, oldest first.
+func (r *VolumeGroupReconciler) storeMetadataBackup(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	key string,
+	backup []byte,
+) (keys []string, err error) {
+	retention := int(max(vg.Spec.MetadataBackup.Retention, 1))
+	objectMeta := metav1.ObjectMeta{Name: metadataBackupName(vg), Namespace: vg.GetNamespace()}
+
+	switch vg.Spec.MetadataBackup.StorageType {
+	case v1alpha1.MetadataBackupStorageTypeSecret:
+		secret := &corev1.Secret{ObjectMeta: objectMeta}
+		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			if secret.Data == nil {
+				secret.Data = make(map[string][]byte)
+			}
+			secret.Data[key] = backup
+			keys = pruneMetadataBackups(secret.Data, retention)
+			return controllerutil.SetControllerReference(vg, secret, r.Scheme)
+		})
+	default:
+		configMap := &corev1.ConfigMap{ObjectMeta: objectMeta}
+		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			configMap.Data[key] = string(backup)
+			keys = pruneMetadataBackups(configMap.Data, retention)
+			return controllerutil.SetControllerReference(vg, configMap, r.Scheme)
+		})
+	}
+	return keys, err
+}
+
+// loadMetadataBackup loads the backup with the key from the object owned by the VolumeGroup.
+func (r *VolumeGroupReconciler) loadMetadataBackup(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	key string,
+) ([]byte, bool, error) {
+	objectKey := client.ObjectKey{Name: metadataBackupName(vg), Namespace: vg.GetNamespace()}
+
+	if vg.Spec.MetadataBackup != nil && vg.Spec.MetadataBackup.StorageType == v1alpha1.MetadataBackupStorageTypeSecret {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, objectKey, secret); err != nil {
+			return nil, false, client.IgnoreNotFound(err)
+		}
+		backup, ok := secret.Data[key]
+		return backup, ok, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, objectKey, configMap); err != nil {
+		return nil, false, client.IgnoreNotFound(err)
+	}
+	backup, ok := configMap.Data[key]
+	return []byte(backup), ok, nil
+}
+
+// restoreMetadataBackup restores the metadata of the volume group from the backup requested with
+// RestoreMetadataBackupAnnotation and removes the annotation afterwards.
+func (r *VolumeGroupReconciler) restoreMetadataBackup(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	key string,
+) (ctrl.Result, error) {
+	name := string(getNameOnNode(vg))
+	logger := log.FromContext(ctx).WithValues("backup", key)
+
+	backup, found, err := r.loadMetadataBackup(ctx, vg, key)
+	if err != nil {
+		err = fmt.Errorf("could not load metadata backup %s: %w", key, err)
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return ctrl.Result{}, classifyError(ctx, errors.Join(err, r.Client.Status().Update(ctx, vg)))
+	}
+	if !found {
+		// Retrying cannot succeed, so the annotation is removed and the failure is only reported.
+		err := fmt.Errorf("metadata backup %s to restore was not found", key)
+		logger.Error(err, "cannot restore metadata backup")
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return ctrl.Result{Requeue: true}, r.removeRestoreAnnotation(ctx, vg)
+	}
+
+	if isPlanOnly(vg) {
+		vg.Status.PlannedOperations = []v1alpha1.PlannedOperation{{
+			Operation: fmt.Sprintf("vgcfgrestore -f %s %s", key, name),
+		}}
+		SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(vg.Status.PlannedOperations))
+		return ctrl.Result{RequeueAfter: r.syncInterval(vg)}, r.Client.Status().Update(ctx, vg)
+	}
+
+	logger.Info("restoring metadata backup")
+	if err := r.withTimeout(ctx, vg, OperationMutation, "vgcfgrestore", func(ctx context.Context) error {
+		return lvmcmd.Restore(ctx, name, backup)
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return ctrl.Result{}, classifyError(ctx, errors.Join(err, r.Client.Status().Update(ctx, vg)))
+	}
+
+	now := metav1.Now()
+	if vg.Status.MetadataBackup == nil {
+		vg.Status.MetadataBackup = &v1alpha1.MetadataBackupStatus{Name: metadataBackupName(vg)}
+	}
+	vg.Status.MetadataBackup.LastRestore = key
+	vg.Status.MetadataBackup.LastRestoreTime = &now
+
+	return ctrl.Result{Requeue: true}, r.removeRestoreAnnotation(ctx, vg)
+}
+
+// removeRestoreAnnotation updates the status and removes RestoreMetadataBackupAnnotation afterwards,
+// as the update of the VolumeGroup replaces the status in memory.
+func (r *VolumeGroupReconciler) removeRestoreAnnotation(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
+	if err := r.Client.Status().Update(ctx, vg); err != nil {
+		return err
+	}
+	annotations := vg.GetAnnotations()
+	delete(annotations, RestoreMetadataBackupAnnotation)
+	vg.SetAnnotations(annotations)
+	if err := r.Update(ctx, vg); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// pruneMetadataBackups removes the oldest backups exceeding the retention and returns the keys of the remaining
+// backups, oldest first.
+func pruneMetadataBackups[V any](data map[string]V, retention int) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for len(keys) > retention {
+		delete(data, keys[0])
+		keys = keys[1:]
+	}
+	return keys
+}
+
+func metadataBackupName(vg *v1alpha1.VolumeGroup) string {
+	return vg.GetName() + "-metadata-backup"
+}
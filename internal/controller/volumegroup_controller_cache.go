@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/selector"
+)
+
+// cacheVolumeSuffix is appended to the name of a logical volume to name its cache volume.
+const cacheVolumeSuffix = "_cache"
+
+// diffPVTags calculates the tags missing on the physical volumes matching selector terms with tags.
+func (r *VolumeGroupReconciler) diffPVTags(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if !slices.ContainsFunc(vg.Spec.PhysicalVolumeSelector, func(term v1alpha1.PVSelectorTerm) bool {
+		return len(term.Tags) > 0
+	}) {
+		return nil, nil
+	}
+
+	var terms [][]string
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		terms, err = selector.DevicesMatchingTerms(ctx, vg.Spec.PhysicalVolumeSelector)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get physical volume names to sync tags: %w", err)
+	}
+	for i := range terms {
+		var err error
+		if terms[i], err = r.physicalVolumesOfDevices(ctx, vg, terms[i]); err != nil {
+			return nil, err
+		}
+	}
+	desired := make(map[string][]string)
+	for i, devices := range terms {
+		for _, device := range devices {
+			desired[device] = append(desired[device], vg.Spec.PhysicalVolumeSelector[i].Tags...)
+		}
+	}
+
+	var pvs []*lvm2go.PhysicalVolume
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get pvs to sync tags: %w", err)
+	}
+
+	var drifts []drift
+	for _, pv := range pvs {
+		var missing []string
+		for _, tag := range desired[string(pv.Name)] {
+			if !slices.Contains(pv.Tags, tag) && !slices.Contains(missing, tag) {
+				missing = append(missing, tag)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		name := string(pv.Name)
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "physicalVolumeSelector",
+				Desired:   fmt.Sprintf("tags=%s", strings.Join(missing, ",")),
+				Actual:    fmt.Sprintf("tags=%s", strings.Join(pv.Tags, ",")),
+				Operation: fmt.Sprintf("pvchange --addtag %s %s", strings.Join(missing, " --addtag "), name),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "pvchange --addtag", func(ctx context.Context) error {
+					return lvmcmd.AddPhysicalVolumeTags(ctx, name, missing)
+				})
+			},
+		})
+	}
+	return drifts, nil
+}
+
+// diffCache calculates the logical volumes that are not cached yet and the caches with a different mode.
+func (r *VolumeGroupReconciler) diffCache(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	cache := vg.Spec.Cache
+	if cache == nil {
+		return nil, nil
+	}
+
+	lvs, err := r.logicalVolumeCaches(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get caches to sync spec: %w", err)
+	}
+	existing := make(map[string]bool, len(lvs))
+	for _, lv := range lvs {
+		existing[lv.Name] = true
+	}
+
+	name := string(lvm.Name)
+	mode := convertToCacheMode(cache.Mode)
+	size := cache.Size.Value()
+
+	var drifts []drift
+	for _, lv := range lvs {
+		if !slices.Contains(cache.LogicalVolumes, lv.Name) &&
+			(cache.LogicalVolumeTag == "" || !slices.Contains(lv.Tags, cache.LogicalVolumeTag)) {
+			continue
+		}
+
+		switch {
+		case lv.Mode == "":
+			lv, cacheVolume := lv.Name, lv.Name+cacheVolumeSuffix
+			operation := fmt.Sprintf("lvconvert --type %s --cachevol %s/%s %s/%s", mode, name, cacheVolume, name, lv)
+			if !existing[cacheVolume] {
+				operation = fmt.Sprintf("lvcreate --name %s --size %db %s @%s, %s",
+					cacheVolume, size, name, cache.PhysicalVolumeTag, operation)
+			}
+			drifts = append(drifts, drift{
+				VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+					Field:     fmt.Sprintf("cache[%s]", lv),
+					Desired:   string(cache.Mode),
+					Actual:    "uncached",
+					Operation: operation,
+				},
+				correct: func(ctx context.Context) error {
+					if !existing[cacheVolume] {
+						if err := r.withTimeout(ctx, vg, OperationLong, "lvcreate", func(ctx context.Context) error {
+							return lvmcmd.CreateCacheVolume(ctx, name, cacheVolume, size, cache.PhysicalVolumeTag)
+						}); err != nil {
+							return err
+						}
+					}
+					return r.withTimeout(ctx, vg, OperationLong, "lvconvert --cachevol", func(ctx context.Context) error {
+						return lvmcmd.AttachCache(ctx, name, lv, cacheVolume, mode)
+					})
+				},
+			})
+		case lv.Mode != mode && (lv.Mode == lvmcmd.CacheModeWritecache || mode == lvmcmd.CacheModeWritecache):
+			// dm-cache and dm-writecache cannot be converted into each other, so the cache volume is detached,
+			// which writes back dirty blocks, and attached again in the desired mode.
+			lv, cacheVolume, actual := lv.Name, lv.CacheVolume, convertFromCacheMode(lv.Mode)
+			drifts = append(drifts, drift{
+				VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+					Field:   fmt.Sprintf("cache[%s].mode", lv),
+					Desired: string(cache.Mode),
+					Actual:  string(actual),
+					Operation: fmt.Sprintf("lvconvert --splitcache %s/%s, lvconvert --type %s --cachevol %s/%s %s/%s",
+						name, lv, mode, name, cacheVolume, name, lv),
+				},
+				correct: func(ctx context.Context) error {
+					if err := r.withTimeout(ctx, vg, OperationLong, "lvconvert --splitcache", func(ctx context.Context) error {
+						return lvmcmd.SplitCache(ctx, name, lv)
+					}); err != nil {
+						return err
+					}
+					return r.withTimeout(ctx, vg, OperationLong, "lvconvert --cachevol", func(ctx context.Context) error {
+						return lvmcmd.AttachCache(ctx, name, lv, cacheVolume, mode)
+					})
+				},
+			})
+		case lv.Mode != mode:
+			lv, actual := lv.Name, convertFromCacheMode(lv.Mode)
+			drifts = append(drifts, drift{
+				VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+					Field:     fmt.Sprintf("cache[%s].mode", lv),
+					Desired:   string(cache.Mode),
+					Actual:    string(actual),
+					Operation: fmt.Sprintf("lvchange --cachemode %s %s/%s", mode, name, lv),
+				},
+				correct: func(ctx context.Context) error {
+					return r.withTimeout(ctx, vg, OperationMutation, "lvchange --cachemode", func(ctx context.Context) error {
+						return lvmcmd.ChangeCacheMode(ctx, name, lv, mode)
+					})
+				},
+			})
+		}
+	}
+	return drifts, nil
+}
+
+// cacheStatus returns the status of the cached logical volumes in the volume group.
+func (r *VolumeGroupReconciler) cacheStatus(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]v1alpha1.CachedLogicalVolumeStatus, error) {
+	if vg.Spec.Cache == nil {
+		return nil, nil
+	}
+	lvs, err := r.logicalVolumeCaches(ctx, vg, lvm)
+	if err != nil {
+		return nil, err
+	}
+	var status []v1alpha1.CachedLogicalVolumeStatus
+	for _, lv := range lvs {
+		if lv.Mode == "" {
+			continue
+		}
+		status = append(status, v1alpha1.CachedLogicalVolumeStatus{
+			Name:        lv.Name,
+			Mode:        convertFromCacheMode(lv.Mode),
+			CacheVolume: lv.CacheVolume,
+			ReadHits:    lv.ReadHits,
+			ReadMisses:  lv.ReadMisses,
+			WriteHits:   lv.WriteHits,
+			WriteMisses: lv.WriteMisses,
+			DirtyBlocks: lv.DirtyBlocks,
+		})
+	}
+	return status, nil
+}
+
+// logicalVolumeCaches returns the logical volumes in the volume group with the statistics of their caches.
+func (r *VolumeGroupReconciler) logicalVolumeCaches(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (lvs []lvmcmd.LogicalVolumeCache, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "lvs", func(ctx context.Context) (err error) {
+		lvs, err = lvmcmd.LogicalVolumeCaches(ctx, string(lvm.Name))
+		return
+	})
+	return
+}
+
+func convertToCacheMode(mode v1alpha1.CacheMode) lvmcmd.CacheMode {
+	switch mode {
+	case v1alpha1.CacheModeWriteback:
+		return lvmcmd.CacheModeWriteback
+	case v1alpha1.CacheModeWritecache:
+		return lvmcmd.CacheModeWritecache
+	}
+	return lvmcmd.CacheModeWritethrough
+}
+
+func convertFromCacheMode(mode lvmcmd.CacheMode) v1alpha1.CacheMode {
+	switch mode {
+	case lvmcmd.CacheModeWriteback:
+		return v1alpha1.CacheModeWriteback
+	case lvmcmd.CacheModeWritecache:
+		return v1alpha1.CacheModeWritecache
+	}
+	return v1alpha1.CacheModeWritethrough
+}
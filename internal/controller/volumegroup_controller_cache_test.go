@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+)
+
+func TestConvertCacheMode(t *testing.T) {
+	for mode, expected := range map[v1alpha1.CacheMode]lvmcmd.CacheMode{
+		"":                             lvmcmd.CacheModeWritethrough,
+		v1alpha1.CacheModeWritethrough: lvmcmd.CacheModeWritethrough,
+		v1alpha1.CacheModeWriteback:    lvmcmd.CacheModeWriteback,
+		v1alpha1.CacheModeWritecache:   lvmcmd.CacheModeWritecache,
+	} {
+		converted := convertToCacheMode(mode)
+		if converted != expected {
+			t.Errorf("expected %q to convert to %q, got %q", mode, expected, converted)
+		}
+		if mode == "" {
+			mode = v1alpha1.CacheModeWritethrough
+		}
+		if roundTrip := convertFromCacheMode(converted); roundTrip != mode {
+			t.Errorf("expected %q to convert back to %q, got %q", converted, mode, roundTrip)
+		}
+	}
+}
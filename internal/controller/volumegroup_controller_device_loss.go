@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceReturnCheckInterval is the interval in which a volume group is synced while waiting for missing physical volumes
+// to return due to DeviceLossSynchronizationPolicyWaitForReturn.
+const DeviceReturnCheckInterval = 10 * time.Second
+
+// defaultDeviceLossGracePeriod is the grace period of DeviceLossSynchronizationPolicyWaitForReturn if not configured.
+const defaultDeviceLossGracePeriod = 5 * time.Minute
+
+// ErrWaitingForDeviceReturn is returned by a sync that waits for missing physical volumes to return.
+var ErrWaitingForDeviceReturn = errors.New("waiting for missing physical volumes to return")
+
+// trackMissingPhysicalVolumes records the missing physical volumes of the volume group in the status.
+// The time since a physical volume is missing is kept across syncs, and only physical volumes listed again without
+// being missing are dropped as returned. The physical volume reported missing by lvm2 is recorded with the path
+// it was last written to, even if it is not listed as missing, and kept until it returns.
+func trackMissingPhysicalVolumes(
+	status *v1alpha1.VolumeGroupStatus,
+	pvs []lvmcmd.PhysicalVolumeState,
+	reported, lastWritePath string,
+	now metav1.Time,
+) {
+	status.MissingPhysicalVolumes = slices.DeleteFunc(status.MissingPhysicalVolumes,
+		func(missing v1alpha1.MissingPhysicalVolumeStatus) bool {
+			return slices.ContainsFunc(pvs, func(pv lvmcmd.PhysicalVolumeState) bool {
+				return pv.UUID == missing.UUID && !pv.Missing
+			})
+		})
+	track := func(uuid string) *v1alpha1.MissingPhysicalVolumeStatus {
+		if i := slices.IndexFunc(status.MissingPhysicalVolumes, func(missing v1alpha1.MissingPhysicalVolumeStatus) bool {
+			return missing.UUID == uuid
+		}); i >= 0 {
+			return &status.MissingPhysicalVolumes[i]
+		}
+		status.MissingPhysicalVolumes = append(status.MissingPhysicalVolumes,
+			v1alpha1.MissingPhysicalVolumeStatus{UUID: uuid, MissingSince: now})
+		return &status.MissingPhysicalVolumes[len(status.MissingPhysicalVolumes)-1]
+	}
+
+	for _, pv := range pvs {
+		if pv.Missing {
+			track(pv.UUID)
+		}
+	}
+	if reported != "" {
+		if missing := track(reported); lastWritePath != "" {
+			missing.LastWritePath = lastWritePath
+		}
+	}
+}
+
+// deviceLossGraceRemaining returns the time until the grace period of the most recently missing physical volume expires.
+// Missing physical volumes are only removed once it is not positive, as vgreduce --removemissing removes all of them.
+func deviceLossGraceRemaining(vg *v1alpha1.VolumeGroup, now time.Time) time.Duration {
+	var remaining time.Duration
+	for _, missing := range vg.Status.MissingPhysicalVolumes {
+		remaining = max(remaining, missing.MissingSince.Add(deviceLossGracePeriod(vg)).Sub(now))
+	}
+	return remaining
+}
+
+// deviceLossGracePeriod returns how long missing physical volumes are waited for with DeviceLossSynchronizationPolicyWaitForReturn.
+func deviceLossGracePeriod(vg *v1alpha1.VolumeGroup) time.Duration {
+	if wait := vg.Spec.DeviceLossWaitForReturn; wait != nil && wait.GracePeriod.Duration > 0 {
+		return wait.GracePeriod.Duration
+	}
+	return defaultDeviceLossGracePeriod
+}
+
+// deviceLossRemovalPolicy returns the policy missing physical volumes are removed with,
+// which is the fallback policy for DeviceLossSynchronizationPolicyWaitForReturn.
+func deviceLossRemovalPolicy(vg *v1alpha1.VolumeGroup) v1alpha1.DeviceLossSynchronizationPolicy {
+	if vg.Spec.DeviceLossSynchronizationPolicy != v1alpha1.DeviceLossSynchronizationPolicyWaitForReturn {
+		return vg.Spec.DeviceLossSynchronizationPolicy
+	}
+	if wait := vg.Spec.DeviceLossWaitForReturn; wait != nil && wait.FallbackPolicy != "" {
+		return wait.FallbackPolicy
+	}
+	return v1alpha1.DeviceLossSynchronizationPolicyRemoveMissing
+}
+
+// deviceReturnRequeueAfter returns when to sync again while waiting for missing physical volumes to return.
+// It is at most DeviceReturnCheckInterval, also if the sync interval is disabled, e.g. for volume groups covered
+// by host events, and the volume group is synced once the grace period expires.
+func deviceReturnRequeueAfter(vg *v1alpha1.VolumeGroup, syncInterval time.Duration, now time.Time) time.Duration {
+	requeueAfter := DeviceReturnCheckInterval
+	if syncInterval > 0 {
+		requeueAfter = min(requeueAfter, syncInterval)
+	}
+	if remaining := deviceLossGraceRemaining(vg, now); remaining > 0 {
+		requeueAfter = min(requeueAfter, remaining)
+	}
+	return requeueAfter
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrackMissingPhysicalVolumes(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(earlier.Add(time.Hour))
+	status := &v1alpha1.VolumeGroupStatus{MissingPhysicalVolumes: []v1alpha1.MissingPhysicalVolumeStatus{
+		{UUID: "still-missing", LastWritePath: "/dev/sdb", MissingSince: earlier},
+		{UUID: "returned", MissingSince: earlier},
+	}}
+
+	trackMissingPhysicalVolumes(status, []lvmcmd.PhysicalVolumeState{
+		{Name: "[unknown]", UUID: "still-missing", Missing: true},
+		{Name: "/dev/sdc", UUID: "returned"},
+		{Name: "[unknown]", UUID: "new", Missing: true},
+	}, "reported", "/dev/sdd", now)
+
+	expected := []v1alpha1.MissingPhysicalVolumeStatus{
+		{UUID: "still-missing", LastWritePath: "/dev/sdb", MissingSince: earlier},
+		{UUID: "new", MissingSince: now},
+		{UUID: "reported", LastWritePath: "/dev/sdd", MissingSince: now},
+	}
+	if len(status.MissingPhysicalVolumes) != len(expected) {
+		t.Fatalf("expected %d missing physical volumes, got %v", len(expected), status.MissingPhysicalVolumes)
+	}
+	for i, missing := range status.MissingPhysicalVolumes {
+		if missing.UUID != expected[i].UUID || missing.LastWritePath != expected[i].LastWritePath ||
+			!missing.MissingSince.Equal(&expected[i].MissingSince) {
+			t.Errorf("expected missing physical volume %v, got %v", expected[i], missing)
+		}
+	}
+
+	// The physical volume reported by lvm2 is kept with the time since it is missing and the path it was last
+	// written to, even if it is not listed, e.g. when the status is summarized without the report of lvm2.
+	later := metav1.NewTime(now.Add(time.Hour))
+	trackMissingPhysicalVolumes(status, []lvmcmd.PhysicalVolumeState{
+		{Name: "/dev/sdb", UUID: "still-missing"},
+		{Name: "/dev/sde", UUID: "new"},
+	}, "", "", later)
+	if len(status.MissingPhysicalVolumes) != 1 || status.MissingPhysicalVolumes[0].UUID != "reported" ||
+		status.MissingPhysicalVolumes[0].LastWritePath != "/dev/sdd" || !status.MissingPhysicalVolumes[0].MissingSince.Equal(&now) {
+		t.Errorf("expected only the reported physical volume to be kept, got %v", status.MissingPhysicalVolumes)
+	}
+}
+
+func TestDeviceLossRemovalPolicy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy   v1alpha1.DeviceLossSynchronizationPolicy
+		wait     *v1alpha1.DeviceLossWaitForReturn
+		expected v1alpha1.DeviceLossSynchronizationPolicy
+	}{
+		"fail": {
+			policy:   v1alpha1.DeviceLossSynchronizationPolicyFail,
+			expected: v1alpha1.DeviceLossSynchronizationPolicyFail,
+		},
+		"force remove": {
+			policy:   v1alpha1.DeviceLossSynchronizationPolicyForceRemoveMissing,
+			expected: v1alpha1.DeviceLossSynchronizationPolicyForceRemoveMissing,
+		},
+		"wait for return": {
+			policy:   v1alpha1.DeviceLossSynchronizationPolicyWaitForReturn,
+			expected: v1alpha1.DeviceLossSynchronizationPolicyRemoveMissing,
+		},
+		"wait for return with fallback": {
+			policy:   v1alpha1.DeviceLossSynchronizationPolicyWaitForReturn,
+			wait:     &v1alpha1.DeviceLossWaitForReturn{FallbackPolicy: v1alpha1.DeviceLossSynchronizationPolicyFail},
+			expected: v1alpha1.DeviceLossSynchronizationPolicyFail,
+		},
+	} {
+		vg := &v1alpha1.VolumeGroup{Spec: v1alpha1.VolumeGroupSpec{
+			DeviceLossSynchronizationPolicy: tc.policy,
+			DeviceLossWaitForReturn:         tc.wait,
+		}}
+		if policy := deviceLossRemovalPolicy(vg); policy != tc.expected {
+			t.Errorf("%s: expected policy %s, got %s", name, tc.expected, policy)
+		}
+	}
+}
+
+func TestDeviceReturnRequeueAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	missing := func(since ...time.Duration) *v1alpha1.VolumeGroup {
+		vg := &v1alpha1.VolumeGroup{Spec: v1alpha1.VolumeGroupSpec{
+			DeviceLossSynchronizationPolicy: v1alpha1.DeviceLossSynchronizationPolicyWaitForReturn,
+			DeviceLossWaitForReturn: &v1alpha1.DeviceLossWaitForReturn{
+				GracePeriod: metav1.Duration{Duration: time.Minute},
+			},
+		}}
+		for _, ago := range since {
+			vg.Status.MissingPhysicalVolumes = append(vg.Status.MissingPhysicalVolumes,
+				v1alpha1.MissingPhysicalVolumeStatus{MissingSince: metav1.NewTime(now.Add(-ago))})
+		}
+		return vg
+	}
+
+	for name, tc := range map[string]struct {
+		vg           *v1alpha1.VolumeGroup
+		syncInterval time.Duration
+		remaining    time.Duration
+		expected     time.Duration
+	}{
+		"sync interval disabled": {
+			vg:        missing(0),
+			remaining: time.Minute,
+			expected:  DeviceReturnCheckInterval,
+		},
+		"shorter sync interval": {
+			vg:           missing(0),
+			syncInterval: time.Second,
+			remaining:    time.Minute,
+			expected:     time.Second,
+		},
+		"grace period about to expire": {
+			vg:        missing(55 * time.Second),
+			remaining: 5 * time.Second,
+			expected:  5 * time.Second,
+		},
+		"grace period of the most recently missing physical volume": {
+			vg:        missing(2*time.Minute, 55*time.Second),
+			remaining: 5 * time.Second,
+			expected:  5 * time.Second,
+		},
+		"grace period expired": {
+			vg:       missing(2 * time.Minute),
+			expected: DeviceReturnCheckInterval,
+		},
+	} {
+		if remaining := deviceLossGraceRemaining(tc.vg, now); remaining != tc.remaining {
+			t.Errorf("%s: expected %s of the grace period remaining, got %s", name, tc.remaining, remaining)
+		}
+		if requeueAfter := deviceReturnRequeueAfter(tc.vg, tc.syncInterval, now); requeueAfter != tc.expected {
+			t.Errorf("%s: expected requeue after %s, got %s", name, tc.expected, requeueAfter)
+		}
+	}
+}
+
+func TestDeviceLossGracePeriod(t *testing.T) {
+	vg := &v1alpha1.VolumeGroup{}
+	if period := deviceLossGracePeriod(vg); period != defaultDeviceLossGracePeriod {
+		t.Errorf("expected default grace period %s, got %s", defaultDeviceLossGracePeriod, period)
+	}
+	vg.Spec.DeviceLossWaitForReturn = &v1alpha1.DeviceLossWaitForReturn{GracePeriod: metav1.Duration{Duration: time.Hour}}
+	if period := deviceLossGracePeriod(vg); period != time.Hour {
+		t.Errorf("expected grace period %s, got %s", time.Hour, period)
+	}
+}
@@ -0,0 +1,370 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/luks"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/lvmerr"
+	"github.com/topolvm/topovgm/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrEncryptedDevicesClosed is returned if the encrypted devices of a volume group in plan mode or with
+// DriftPolicyReport are closed, as opening them is only planned or reported.
+var ErrEncryptedDevicesClosed = errors.New("encrypted devices are closed")
+
+// encryptionKeys returns the passphrase and the previous passphrase of the encrypted devices of the volume group
+// and the resource version of the Secret they are read from.
+func (r *VolumeGroupReconciler) encryptionKeys(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+) (key, previous []byte, version string, err error) {
+	secret := &corev1.Secret{}
+	name := vg.Spec.DevicePreparation.Encryption.SecretName
+	if err := r.Get(ctx, client.ObjectKey{Namespace: vg.GetNamespace(), Name: name}, secret); err != nil {
+		return nil, nil, "", fmt.Errorf("could not get Secret with encryption passphrase: %w", err)
+	}
+	key = secret.Data[v1alpha1.EncryptionPassphraseKey]
+	if len(key) == 0 {
+		return nil, nil, "", fmt.Errorf("secret %s does not contain the key %s", name, v1alpha1.EncryptionPassphraseKey)
+	}
+	return key, secret.Data[v1alpha1.EncryptionPreviousPassphraseKey], secret.GetResourceVersion(), nil
+}
+
+// openEncryptedDevices opens the dm-crypt mappings of the LUKS devices matched by the selector once after startup,
+// e.g. after a reboot of the node, so that the physical volumes of the volume group can be discovered.
+func (r *VolumeGroupReconciler) openEncryptedDevices(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
+	if !isEncrypted(vg) {
+		return nil
+	}
+	if _, opened := r.encryptionOpened.Load(vg.GetUID()); opened {
+		return nil
+	}
+
+	devices, err := r.luksDevices(ctx, vg)
+	if err != nil {
+		return err
+	}
+
+	var closed []v1alpha1.VolumeGroupDrift
+	for _, device := range devices {
+		if device.UUID != "" && device.Mapping == "" {
+			closed = append(closed, v1alpha1.VolumeGroupDrift{
+				Field:     "devicePreparation.encryption",
+				Desired:   "open",
+				Actual:    "closed",
+				Operation: fmt.Sprintf("cryptsetup open %s %s", device.Path, luks.MappingName(device.UUID)),
+			})
+		}
+	}
+	if len(closed) > 0 && (isPlanOnly(vg) || vg.Spec.DriftPolicy == v1alpha1.DriftPolicyReport) {
+		// Opening the mappings changes the node, so it is only planned or reported. Without the mappings,
+		// the volume group cannot be discovered, so it is not synced any further.
+		if isPlanOnly(vg) {
+			vg.Status.PlannedOperations = planDrift(closed)
+			SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(closed))
+		} else {
+			vg.Status.Drift = closed
+			SetDriftedReported(&vg.Status.Conditions, vg.GetGeneration(), len(closed))
+		}
+		return ErrEncryptedDevicesClosed
+	}
+
+	var key, previous []byte
+	for _, device := range devices {
+		if device.UUID == "" || device.Mapping != "" {
+			continue
+		}
+		if key == nil {
+			if key, previous, _, err = r.encryptionKeys(ctx, vg); err != nil {
+				return err
+			}
+		}
+		if err := r.openEncryptedDevice(ctx, vg, device, key, previous); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("opened encrypted device", "device", device.Path, "mapping", luks.MappingPath(device.UUID))
+	}
+
+	r.encryptionOpened.Store(vg.GetUID(), true)
+	return nil
+}
+
+// prepareEncryptedDevices maps the devices to their dm-crypt mappings, which are used as physical volumes instead.
+// Devices that are not formatted with LUKS or not open yet need to be prepared by the returned drifts first.
+func (r *VolumeGroupReconciler) prepareEncryptedDevices(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	names []lvm2go.PhysicalVolumeName,
+) ([]lvm2go.PhysicalVolumeName, []drift, error) {
+	if !isEncrypted(vg) {
+		return names, nil, nil
+	}
+
+	paths := utils.Map(names, func(name lvm2go.PhysicalVolumeName) string { return string(name) })
+	var devices map[string]luks.Device
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		devices, err = luks.Devices(ctx, paths)
+		return
+	}); err != nil {
+		return nil, nil, fmt.Errorf("could not get encrypted devices: %w", err)
+	}
+
+	var pvs []lvmcmd.PhysicalVolumeState
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = lvmcmd.PhysicalVolumeStates(ctx, "")
+		return
+	}); err != nil {
+		return nil, nil, fmt.Errorf("could not get physical volumes to prepare encrypted devices: %w", err)
+	}
+
+	mappings := make([]lvm2go.PhysicalVolumeName, 0, len(paths))
+	var drifts []drift
+	for _, path := range paths {
+		device, ok := devices[path]
+		if !ok {
+			// Without the state of the device, it is unknown whether it is blank, so it is never formatted.
+			return nil, nil, fmt.Errorf("could not find block device %s to prepare it for encryption", path)
+		}
+		switch {
+		case device.UUID == "" && (!device.Blank() || slices.ContainsFunc(pvs, func(pv lvmcmd.PhysicalVolumeState) bool {
+			return pv.Name == path
+		})):
+			// Formatting a device in use, e.g. a physical volume of the volume group before encryption was enabled
+			// or a device with a filesystem, destroys its data, so only blank devices are formatted.
+			return nil, nil, lvmerr.NewTerminal(fmt.Errorf("refusing to format device %s with LUKS, "+
+				"it is not blank as it carries a signature, partitions or a physical volume", path))
+		case device.Mapping != "":
+		case device.UUID != "":
+			drifts = append(drifts, drift{
+				VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+					Field:     "devicePreparation.encryption",
+					Desired:   "open",
+					Actual:    "closed",
+					Operation: fmt.Sprintf("cryptsetup open %s %s", path, luks.MappingName(device.UUID)),
+				},
+				correct: func(ctx context.Context) error {
+					key, previous, _, err := r.encryptionKeys(ctx, vg)
+					if err != nil {
+						return err
+					}
+					return r.openEncryptedDevice(ctx, vg, device, key, previous)
+				},
+			})
+		default:
+			device.UUID = string(uuid.NewUUID())
+			drifts = append(drifts, drift{
+				VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+					Field:   "devicePreparation.encryption",
+					Desired: "encrypted",
+					Actual:  "unencrypted",
+					Operation: fmt.Sprintf("cryptsetup luksFormat --type luks2 --uuid %s %s, cryptsetup open %s %s",
+						device.UUID, path, path, luks.MappingName(device.UUID)),
+				},
+				correct: func(ctx context.Context) error {
+					key, _, _, err := r.encryptionKeys(ctx, vg)
+					if err != nil {
+						return err
+					}
+					if err := r.withTimeout(ctx, vg, OperationLong, "cryptsetup luksFormat", func(ctx context.Context) error {
+						return luks.Format(ctx, path, device.UUID, key)
+					}); err != nil {
+						return err
+					}
+					return r.openEncryptedDevice(ctx, vg, device, key, nil)
+				},
+			})
+		}
+		mappings = append(mappings, lvm2go.PhysicalVolumeName(luks.MappingPath(device.UUID)))
+	}
+	return mappings, drifts, nil
+}
+
+// physicalVolumesOfDevices maps the devices to the names of their physical volumes,
+// which are the open dm-crypt mappings of encrypted devices.
+func (r *VolumeGroupReconciler) physicalVolumesOfDevices(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	paths []string,
+) ([]string, error) {
+	if !isEncrypted(vg) {
+		return paths, nil
+	}
+	var devices map[string]luks.Device
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		devices, err = luks.Devices(ctx, paths)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get encrypted devices: %w", err)
+	}
+	var pvs []string
+	for _, path := range paths {
+		if mapping := devices[path].Mapping; mapping != "" {
+			pvs = append(pvs, mapping)
+		}
+	}
+	return pvs, nil
+}
+
+// diffEncryptionKey calculates the encrypted devices that cannot be opened with the passphrase of the Secret
+// once its resource version changed, and rotates them from the previous passphrase to the passphrase.
+func (r *VolumeGroupReconciler) diffEncryptionKey(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	_ *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	status := vg.Status.Encryption
+	if !isEncrypted(vg) || status == nil {
+		return nil, nil
+	}
+	key, previous, version, err := r.encryptionKeys(ctx, vg)
+	if err != nil {
+		return nil, err
+	}
+	if status.KeyVersion == version {
+		return nil, nil
+	}
+
+	var drifts []drift
+	for _, device := range status.Devices {
+		if err := r.withTimeout(ctx, vg, OperationMutation, "cryptsetup open --test-passphrase", func(ctx context.Context) error {
+			return luks.TestKey(ctx, device.Device, key)
+		}); err == nil {
+			continue
+		}
+		if len(previous) == 0 {
+			return nil, lvmerr.NewTerminal(fmt.Errorf("the passphrase does not open the encrypted device %s "+
+				"and no previous passphrase to rotate from is set", device.Device))
+		}
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "devicePreparation.encryption",
+				Desired:   fmt.Sprintf("key version %s", version),
+				Actual:    fmt.Sprintf("key version %s", status.KeyVersion),
+				Operation: fmt.Sprintf("cryptsetup luksAddKey %s, cryptsetup luksRemoveKey %s", device.Device, device.Device),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationLong, "cryptsetup luksAddKey", func(ctx context.Context) error {
+					return luks.RotateKey(ctx, device.Device, previous, key)
+				})
+			},
+		})
+	}
+
+	if len(drifts) == 0 {
+		status.KeyVersion = version
+	}
+	return drifts, nil
+}
+
+// encryptionStatus returns the encrypted devices whose mappings are physical volumes of the volume group.
+func (r *VolumeGroupReconciler) encryptionStatus(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	pvs []*lvm2go.PhysicalVolume,
+) (*v1alpha1.EncryptionStatus, error) {
+	if !isEncrypted(vg) {
+		return nil, nil
+	}
+	devices, err := r.luksDevices(ctx, vg)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &v1alpha1.EncryptionStatus{}
+	if vg.Status.Encryption != nil {
+		status.KeyVersion = vg.Status.Encryption.KeyVersion
+	}
+	for _, device := range devices {
+		if device.Mapping == "" {
+			continue
+		}
+		for _, pv := range pvs {
+			if string(pv.Name) == device.Mapping {
+				status.Devices = append(status.Devices, v1alpha1.EncryptedDeviceStatus{
+					Device:  device.Path,
+					UUID:    device.UUID,
+					Mapping: device.Mapping,
+				})
+			}
+		}
+	}
+	if status.KeyVersion == "" {
+		// The devices were encrypted with the passphrase read on creation.
+		if _, _, status.KeyVersion, err = r.encryptionKeys(ctx, vg); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// closeEncryptedDevices closes the dm-crypt mappings of the encrypted devices after the volume group was removed.
+// The LUKS headers are kept on the devices.
+func (r *VolumeGroupReconciler) closeEncryptedDevices(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
+	if vg.Status.Encryption == nil {
+		return nil
+	}
+	var errs []error
+	for _, device := range vg.Status.Encryption.Devices {
+		errs = append(errs, r.withTimeout(ctx, vg, OperationMutation, "cryptsetup close", func(ctx context.Context) error {
+			return luks.Close(ctx, device.UUID)
+		}))
+	}
+	r.encryptionOpened.Delete(vg.GetUID())
+	return errors.Join(errs...)
+}
+
+// openEncryptedDevice opens the dm-crypt mapping of the device with the passphrase or the previous passphrase.
+func (r *VolumeGroupReconciler) openEncryptedDevice(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	device luks.Device,
+	key, previous []byte,
+) error {
+	err := r.withTimeout(ctx, vg, OperationMutation, "cryptsetup open", func(ctx context.Context) error {
+		return luks.Open(ctx, device.Path, device.UUID, key)
+	})
+	if err != nil && len(previous) > 0 {
+		err = r.withTimeout(ctx, vg, OperationMutation, "cryptsetup open", func(ctx context.Context) error {
+			return luks.Open(ctx, device.Path, device.UUID, previous)
+		})
+	}
+	return err
+}
+
+// luksDevices returns the LUKS state of the devices matched by the selector.
+func (r *VolumeGroupReconciler) luksDevices(ctx context.Context, vg *v1alpha1.VolumeGroup) ([]luks.Device, error) {
+	var devices []luks.Device
+	err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) error {
+		names, err := getPhysicalVolumeNames(ctx, vg)
+		if err != nil {
+			return err
+		}
+		paths := utils.Map(names, func(name lvm2go.PhysicalVolumeName) string { return string(name) })
+		byPath, err := luks.Devices(ctx, paths)
+		for _, path := range paths {
+			if device, ok := byPath[path]; ok {
+				devices = append(devices, device)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get encrypted devices: %w", err)
+	}
+	return devices, nil
+}
+
+// isEncrypted returns true if the devices of the volume group are encrypted.
+func isEncrypted(vg *v1alpha1.VolumeGroup) bool {
+	return vg.Spec.DevicePreparation != nil && vg.Spec.DevicePreparation.Encryption != nil
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/lvmerr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// isExported returns true if the volume group is exported on the node.
+func isExported(lvm *lvm2go.VolumeGroup) bool {
+	// The third attribute is the export state of the volume group, which is 'x' if it is exported.
+	attr := lvm.Attr.String()
+	return len(attr) > 2 && attr[2] == 'x'
+}
+
+// diffExport calculates the difference between the desired and the actual export state of the volume group.
+// It is the only differ run for exported volume groups, as they cannot be changed until they are imported.
+func (r *VolumeGroupReconciler) diffExport(
+	_ context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	exported := isExported(lvm)
+	if vg.Spec.Exported == exported {
+		return nil, nil
+	}
+
+	name := string(lvm.Name)
+	if vg.Spec.Exported {
+		return []drift{{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "exported",
+				Desired:   "true",
+				Actual:    "false",
+				Operation: fmt.Sprintf("vgchange %s -an, vgexport %s", name, name),
+			},
+			correct: func(ctx context.Context) error {
+				if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange -an", func(ctx context.Context) error {
+					return lvmcmd.ChangeActivation(ctx, name, lvmcmd.Deactivate)
+				}); err != nil {
+					return err
+				}
+				return r.withTimeout(ctx, vg, OperationMutation, "vgexport", func(ctx context.Context) error {
+					return lvmcmd.Export(ctx, name)
+				})
+			},
+		}}, nil
+	}
+
+	// Only import volume groups that were exported by this VolumeGroup or that should be adopted by it,
+	// an unrelated exported volume group with the same name could be in the middle of a migration.
+	if lvm.UUID != vg.Status.UUID && (vg.Spec.Import == nil || vg.Spec.Import.UUID != lvm.UUID) {
+		return nil, lvmerr.NewTerminal(fmt.Errorf(
+			"volume group %s with UUID %s is exported on the node, set .spec.import.uuid to import it", name, lvm.UUID))
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "exported",
+			Desired:   "false",
+			Actual:    "true",
+			Operation: fmt.Sprintf("vgimport %s", name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgimport", func(ctx context.Context) error {
+				return lvmcmd.Import(ctx, name)
+			})
+		},
+	}}, nil
+}
+
+// importVG imports the exported volume group with the UUID from the spec and renames it to the name on the node.
+func (r *VolumeGroupReconciler) importVG(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
+	logger := log.FromContext(ctx).WithValues("uuid", vg.Spec.Import.UUID)
+
+	var found string
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		found, err = lvmcmd.VolumeGroupNameByUUID(ctx, vg.Spec.Import.UUID)
+		return
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
+	}
+	if found == "" {
+		// The disks may not be attached to the node yet, so this is retried.
+		err := fmt.Errorf("volume group with UUID %s to import was not found on the node", vg.Spec.Import.UUID)
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
+	}
+
+	name := getNameOnNode(vg)
+	if isPlanOnly(vg) {
+		vg.Status.PlannedOperations = []v1alpha1.PlannedOperation{{Field: "import", Operation: fmt.Sprintf("vgimport %s", found)}}
+		if found != string(name) {
+			vg.Status.PlannedOperations = append(vg.Status.PlannedOperations, v1alpha1.PlannedOperation{
+				Field:     "nameOnNode",
+				Operation: fmt.Sprintf("vgrename %s %s", found, name),
+			})
+		}
+		SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(vg.Status.PlannedOperations))
+		return nil
+	}
+
+	logger.Info("importing volume group on host", "found", found)
+	if err := r.withTimeout(ctx, vg, OperationMutation, "vgimport", func(ctx context.Context) error {
+		return lvmcmd.Import(ctx, found)
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return err
+	}
+
+	if found != string(name) {
+		if err := r.withTimeout(ctx, vg, OperationMutation, "vgrename", func(ctx context.Context) error {
+			return r.LVM.VGRename(ctx, lvm2go.VolumeGroupName(found), name)
+		}); err != nil {
+			SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+			return err
+		}
+	}
+
+	vg.Status.UUID = vg.Spec.Import.UUID
+	return nil
+}
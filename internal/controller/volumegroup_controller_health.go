@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/health"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+)
+
+// defaultIOErrorThreshold is the IOErrorThreshold of DeviceHealth if not set, e.g. if it bypassed defaulting.
+const defaultIOErrorThreshold = 10
+
+// HealthMonitor reads the health signals of the device of a physical volume.
+type HealthMonitor interface {
+	Check(ctx context.Context, path string, major, minor int64) (health.Report, error)
+}
+
+// physicalVolumeHealth returns the health of the device of the physical volume, or nil if it is not monitored.
+func (r *VolumeGroupReconciler) physicalVolumeHealth(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	pv *lvm2go.PhysicalVolume,
+) *v1alpha1.PhysicalVolumeHealth {
+	if vg.Spec.DeviceHealth == nil || r.Health == nil {
+		return nil
+	}
+
+	var report health.Report
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "smartctl", func(ctx context.Context) (err error) {
+		report, err = r.Health.Check(ctx, string(pv.Name), pv.Major, pv.Minor)
+		return
+	}); err != nil {
+		return &v1alpha1.PhysicalVolumeHealth{State: v1alpha1.PhysicalVolumeHealthUnknown, Message: err.Error()}
+	}
+	return convertToPhysicalVolumeHealth(report, vg.Spec.DeviceHealth.IOErrorThreshold)
+}
+
+// diffDeviceHealth calculates the physical volumes of failing devices that are still allocatable
+// if they are marked as non-allocatable by the FailingDevicePolicy.
+// The health is the one reported by the previous sync.
+func (r *VolumeGroupReconciler) diffDeviceHealth(
+	_ context.Context,
+	vg *v1alpha1.VolumeGroup,
+	_ *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if vg.Spec.DeviceHealth == nil || vg.Spec.DeviceHealth.FailingDevicePolicy != v1alpha1.FailingDevicePolicyMarkNonAllocatable {
+		return nil, nil
+	}
+
+	var drifts []drift
+	for _, pv := range vg.Status.PhysicalVolumes {
+		// The first attribute of a physical volume is 'a' if it is allocatable.
+		if pv.Health == nil || pv.Health.State != v1alpha1.PhysicalVolumeFailing || !strings.HasPrefix(pv.Attributes, "a") {
+			continue
+		}
+		name := pv.Name
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "deviceHealth.failingDevicePolicy",
+				Desired:   "allocatable=n",
+				Actual:    "allocatable=y",
+				Operation: fmt.Sprintf("pvchange --allocatable n %s", name),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "pvchange --allocatable", func(ctx context.Context) error {
+					return lvmcmd.ChangeAllocatable(ctx, name, false)
+				})
+			},
+		})
+	}
+	return drifts, nil
+}
+
+// convertToPhysicalVolumeHealth derives the health of a device from its health signals.
+// A threshold that is not positive is replaced by the default, as it would consider every device failing.
+func convertToPhysicalVolumeHealth(report health.Report, ioErrorThreshold int64) *v1alpha1.PhysicalVolumeHealth {
+	if ioErrorThreshold <= 0 {
+		ioErrorThreshold = defaultIOErrorThreshold
+	}
+	status := &v1alpha1.PhysicalVolumeHealth{
+		State:       v1alpha1.PhysicalVolumeHealthy,
+		IOErrors:    report.IOErrors,
+		Suspended:   report.Suspended,
+		SMARTPassed: report.SMARTPassed,
+	}
+
+	var failing, degraded []string
+	if report.SMARTPassed != nil && !*report.SMARTPassed {
+		failing = append(failing, "the SMART self-assessment failed")
+	}
+	if report.IOErrors >= ioErrorThreshold {
+		failing = append(failing, fmt.Sprintf("%d I/O errors reached the threshold of %d", report.IOErrors, ioErrorThreshold))
+	} else if report.IOErrors > 0 {
+		degraded = append(degraded, fmt.Sprintf("%d I/O errors", report.IOErrors))
+	}
+	if report.Suspended {
+		degraded = append(degraded, "the device-mapper device is suspended")
+	}
+
+	switch {
+	case len(failing) > 0:
+		status.State = v1alpha1.PhysicalVolumeFailing
+	case len(degraded) > 0:
+		status.State = v1alpha1.PhysicalVolumeDegraded
+	}
+	status.Message = strings.Join(append(failing, degraded...), ", ")
+	return status
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/health"
+)
+
+func TestConvertToPhysicalVolumeHealth(t *testing.T) {
+	for name, tc := range map[string]struct {
+		report    health.Report
+		threshold int64
+		expected  v1alpha1.PhysicalVolumeHealthState
+	}{
+		"healthy":                         {threshold: 10, expected: v1alpha1.PhysicalVolumeHealthy},
+		"io errors below threshold":       {report: health.Report{IOErrors: 9}, threshold: 10, expected: v1alpha1.PhysicalVolumeDegraded},
+		"io errors reaching threshold":    {report: health.Report{IOErrors: 10}, threshold: 10, expected: v1alpha1.PhysicalVolumeFailing},
+		"suspended":                       {report: health.Report{Suspended: true}, threshold: 10, expected: v1alpha1.PhysicalVolumeDegraded},
+		"smart failed":                    {report: health.Report{SMARTPassed: ptr(false)}, threshold: 10, expected: v1alpha1.PhysicalVolumeFailing},
+		"unset threshold":                 {expected: v1alpha1.PhysicalVolumeHealthy},
+		"io errors below unset threshold": {report: health.Report{IOErrors: 1}, expected: v1alpha1.PhysicalVolumeDegraded},
+		"io errors reaching default":      {report: health.Report{IOErrors: defaultIOErrorThreshold}, expected: v1alpha1.PhysicalVolumeFailing},
+	} {
+		if state := convertToPhysicalVolumeHealth(tc.report, tc.threshold).State; state != tc.expected {
+			t.Errorf("%s: expected state %s, got %s", name, tc.expected, state)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+// LVMDConfigKey is the key of the lvmd configuration in the ConfigMap rendered for a node.
+const LVMDConfigKey = "lvmd.yaml"
+
+const gibibyte = 1 << 30
+
+// lvmdConfig is the part of the configuration of lvmd rendered from the VolumeGroups on a node.
+type lvmdConfig struct {
+	DeviceClasses []lvmdDeviceClass `json:"device-classes"`
+}
+
+type lvmdDeviceClass struct {
+	Name            string        `json:"name"`
+	VolumeGroup     string        `json:"volume-group"`
+	Default         bool          `json:"default,omitempty"`
+	SpareGB         *int64        `json:"spare-gb,omitempty"`
+	Type            string        `json:"type,omitempty"`
+	ThinPool        *lvmdThinPool `json:"thin-pool,omitempty"`
+	LVCreateOptions []string      `json:"lvcreate-options,omitempty"`
+}
+
+type lvmdThinPool struct {
+	Name               string  `json:"name"`
+	OverprovisionRatio float64 `json:"overprovision-ratio"`
+}
+
+// LVMDConfigMapName returns the name of the ConfigMap with the lvmd configuration rendered for the node.
+func LVMDConfigMapName(nodeName string) string {
+	return "topovgm-lvmd-" + nodeName
+}
+
+// renderLVMDConfig renders the device classes of all VolumeGroups on the node that opted in through
+// VolumeGroupSpec.TopoLVM into the lvmd configuration ConfigMap of the node.
+// Only volume groups that exist on the node, i.e. have a name in their status, are rendered.
+func (r *VolumeGroupReconciler) renderLVMDConfig(ctx context.Context) error {
+	if r.LVMDConfigNamespace == "" {
+		return nil
+	}
+
+	vgs := &v1alpha1.VolumeGroupList{}
+	if err := r.List(ctx, vgs); err != nil {
+		return fmt.Errorf("could not list volume groups to render lvmd configuration: %w", err)
+	}
+	slices.SortFunc(vgs.Items, func(a, b v1alpha1.VolumeGroup) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	config := lvmdConfig{DeviceClasses: []lvmdDeviceClass{}}
+	for _, vg := range vgs.Items {
+		class := vg.Spec.TopoLVM
+		if vg.Spec.NodeName != r.NodeName || class == nil || vg.Status.Name == "" || !vg.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if slices.ContainsFunc(config.DeviceClasses, func(c lvmdDeviceClass) bool { return c.Name == class.Name }) {
+			log.FromContext(ctx).Error(fmt.Errorf("duplicate device class %s", class.Name),
+				"skipping volume group in lvmd configuration", "volumegroup", client.ObjectKeyFromObject(&vg))
+			continue
+		}
+		deviceClass, err := convertToLVMDDeviceClass(&vg)
+		if err != nil {
+			return err
+		}
+		config.DeviceClasses = append(config.DeviceClasses, deviceClass)
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("could not marshal lvmd configuration: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: r.LVMDConfigNamespace,
+		Name:      LVMDConfigMapName(r.NodeName),
+	}}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Data = map[string]string{LVMDConfigKey: string(data)}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not store lvmd configuration: %w", err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.FromContext(ctx).Info("rendered lvmd configuration", "configmap", client.ObjectKeyFromObject(cm),
+			"deviceClasses", len(config.DeviceClasses))
+	}
+	return nil
+}
+
+func convertToLVMDDeviceClass(vg *v1alpha1.VolumeGroup) (lvmdDeviceClass, error) {
+	class := vg.Spec.TopoLVM
+	deviceClass := lvmdDeviceClass{
+		Name:            class.Name,
+		VolumeGroup:     vg.Status.Name,
+		Default:         class.Default,
+		SpareGB:         spareGB(vg),
+		Type:            string(class.Type),
+		LVCreateOptions: class.LVCreateOptions,
+	}
+	if class.Type == v1alpha1.TopoLVMDeviceClassTypeThin && class.ThinPool != nil {
+		ratio, err := strconv.ParseFloat(class.ThinPool.OverprovisionRatio, 64)
+		if err != nil {
+			return lvmdDeviceClass{}, fmt.Errorf("could not parse overprovision ratio of device class %s: %w", class.Name, err)
+		}
+		deviceClass.ThinPool = &lvmdThinPool{Name: class.ThinPool.Name, OverprovisionRatio: ratio}
+	}
+	return deviceClass, nil
+}
+
+// spareGB returns the spare capacity in GiB of the device class, which defaults to the capacity reserved in the volume group.
+func spareGB(vg *v1alpha1.VolumeGroup) *int64 {
+	if vg.Spec.TopoLVM.SpareGB != nil || vg.Spec.ReservedCapacity == nil {
+		return vg.Spec.TopoLVM.SpareGB
+	}
+	gb := (reservedCapacity(vg) + gibibyte - 1) / gibibyte
+	return &gb
+}
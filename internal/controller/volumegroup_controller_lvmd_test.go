@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestConvertToLVMDDeviceClass(t *testing.T) {
+	vg := &v1alpha1.VolumeGroup{
+		Spec: v1alpha1.VolumeGroupSpec{TopoLVM: &v1alpha1.TopoLVMDeviceClass{
+			Name:            "ssd",
+			Default:         true,
+			Type:            v1alpha1.TopoLVMDeviceClassTypeThin,
+			ThinPool:        &v1alpha1.TopoLVMThinPool{Name: "pool", OverprovisionRatio: "2.5"},
+			LVCreateOptions: []string{"--type=raid1"},
+		}},
+		Status: v1alpha1.VolumeGroupStatus{Name: "vg1"},
+	}
+	deviceClass, err := convertToLVMDDeviceClass(vg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := lvmdDeviceClass{
+		Name:            "ssd",
+		VolumeGroup:     "vg1",
+		Default:         true,
+		Type:            "thin",
+		ThinPool:        &lvmdThinPool{Name: "pool", OverprovisionRatio: 2.5},
+		LVCreateOptions: []string{"--type=raid1"},
+	}
+	if !reflect.DeepEqual(deviceClass, expected) {
+		t.Errorf("expected device class %+v, got %+v", expected, deviceClass)
+	}
+
+	vg.Spec.TopoLVM.ThinPool.OverprovisionRatio = "many"
+	if _, err := convertToLVMDDeviceClass(vg); err == nil {
+		t.Errorf("expected an error for an invalid overprovision ratio")
+	}
+}
+
+func TestSpareGB(t *testing.T) {
+	size := resource.MustParse("100Gi")
+	vg := &v1alpha1.VolumeGroup{
+		Spec:   v1alpha1.VolumeGroupSpec{TopoLVM: &v1alpha1.TopoLVMDeviceClass{Name: "ssd"}},
+		Status: v1alpha1.VolumeGroupStatus{Size: &size},
+	}
+	if gb := spareGB(vg); gb != nil {
+		t.Errorf("expected no spare capacity without reserve, got %d", *gb)
+	}
+
+	vg.Spec.ReservedCapacity = &v1alpha1.ReservedCapacity{Percent: ptr[int32](5)}
+	if gb := spareGB(vg); gb == nil || *gb != 5 {
+		t.Errorf("expected the reserve of 5Gi as spare capacity, got %v", gb)
+	}
+
+	reserve := resource.MustParse("1500Mi")
+	vg.Spec.ReservedCapacity = &v1alpha1.ReservedCapacity{Size: &reserve}
+	if gb := spareGB(vg); gb == nil || *gb != 2 {
+		t.Errorf("expected the reserve to be rounded up to 2Gi, got %v", gb)
+	}
+
+	vg.Spec.TopoLVM.SpareGB = ptr[int64](10)
+	if gb := spareGB(vg); gb == nil || *gb != 10 {
+		t.Errorf("expected the spare capacity of the device class to take precedence, got %v", gb)
+	}
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/selector"
+)
+
+// diffMetadataCopies calculates the difference in the number of metadata copies of the volume group.
+func (r *VolumeGroupReconciler) diffMetadataCopies(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if vg.Spec.MetadataCopies == nil {
+		return nil, nil
+	}
+
+	actual, err := r.metadataCopies(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get metadata copies to sync spec: %w", err)
+	}
+
+	desired := *vg.Spec.MetadataCopies
+	if actual == desired {
+		return nil, nil
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "metadataCopies",
+			Desired:   strconv.FormatInt(desired, 10),
+			Actual:    strconv.FormatInt(actual, 10),
+			Operation: fmt.Sprintf("vgchange %s --vgmetadatacopies %d", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --vgmetadatacopies", func(ctx context.Context) error {
+				return lvmcmd.ChangeMetadataCopies(ctx, string(lvm.Name), desired)
+			})
+		},
+	}}, nil
+}
+
+// diffMetadataIgnore calculates the difference between the desired and the actual ignored metadata areas
+// of the physical volumes matching selector terms with MetadataIgnore.
+func (r *VolumeGroupReconciler) diffMetadataIgnore(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	managed := false
+	for _, term := range vg.Spec.PhysicalVolumeSelector {
+		managed = managed || term.MetadataIgnore != nil
+	}
+	if !managed {
+		return nil, nil
+	}
+
+	var terms [][]string
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		terms, err = selector.DevicesMatchingTerms(ctx, vg.Spec.PhysicalVolumeSelector)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get physical volume names to sync metadata ignore: %w", err)
+	}
+	for i := range terms {
+		var err error
+		if terms[i], err = r.physicalVolumesOfDevices(ctx, vg, terms[i]); err != nil {
+			return nil, err
+		}
+	}
+	desired := make(map[string]bool)
+	for i, devices := range terms {
+		ignore := vg.Spec.PhysicalVolumeSelector[i].MetadataIgnore
+		if ignore == nil {
+			continue
+		}
+		for _, device := range devices {
+			if _, set := desired[device]; !set {
+				desired[device] = *ignore
+			}
+		}
+	}
+
+	var pvs []*lvm2go.PhysicalVolume
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get pvs to sync metadata ignore: %w", err)
+	}
+
+	var ignore, use []string
+	for _, pv := range pvs {
+		want, ok := desired[string(pv.Name)]
+		if !ok || pv.MdaCount == 0 {
+			continue
+		}
+		// Metadata areas are ignored if none of them is used.
+		if ignored := pv.MdaUsedCount == 0; want && !ignored {
+			ignore = append(ignore, string(pv.Name))
+		} else if !want && ignored {
+			use = append(use, string(pv.Name))
+		}
+	}
+
+	var drifts []drift
+	for _, change := range []struct {
+		pvs    []string
+		ignore bool
+	}{{use, false}, {ignore, true}} {
+		if len(change.pvs) == 0 {
+			continue
+		}
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "physicalVolumeSelector",
+				Desired:   fmt.Sprintf("metadataIgnore=%t", change.ignore),
+				Actual:    fmt.Sprintf("metadataIgnore=%t", !change.ignore),
+				Operation: fmt.Sprintf("pvchange --metadataignore %s %s", convertToYesNo(change.ignore), strings.Join(change.pvs, " ")),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "pvchange --metadataignore", func(ctx context.Context) error {
+					return lvmcmd.ChangeMetadataIgnore(ctx, change.pvs, change.ignore)
+				})
+			},
+		})
+	}
+	return drifts, nil
+}
+
+// metadataCopies returns the number of metadata copies the volume group keeps, or 0 if it is unmanaged.
+func (r *VolumeGroupReconciler) metadataCopies(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (copies int64, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		copies, err = lvmcmd.MetadataCopies(ctx, string(lvm.Name))
+		return
+	})
+	return
+}
+
+func convertToYesNo(b bool) string {
+	if b {
+		return "y"
+	}
+	return "n"
+}
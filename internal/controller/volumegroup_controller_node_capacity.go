@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NodeCapacityAnnotationPrefix is the prefix of the Node annotations publishing the allocatable capacity
+	// of the volume groups on the node, followed by the name of the volume group on the node, see nodeCapacityKey.
+	NodeCapacityAnnotationPrefix = "capacity.topovgm.topolvm.io/"
+	// NodeCapacityResourcePrefix is the prefix of the extended resources of a Node publishing the allocatable capacity
+	// of the volume groups on the node, followed by the name of the volume group on the node, see nodeCapacityKey.
+	NodeCapacityResourcePrefix = "topovgm.topolvm.io/"
+
+	// nodeCapacityKeyMaxLength is the maximum length of the name part of a qualified name.
+	nodeCapacityKeyMaxLength = 63
+	// nodeCapacityHashLength is the number of hex digits of the hash suffix of an encoded volume group name.
+	nodeCapacityHashLength = 8
+)
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;patch
+// +kubebuilder:rbac:groups="",resources=nodes/status,verbs=get;patch
+
+// publishNodeCapacity publishes the allocatable capacity of the volume group on its Node and removes the capacity
+// published under a previous name of the volume group. If the volume group is being deleted, its capacity is removed.
+func (r *VolumeGroupReconciler) publishNodeCapacity(ctx context.Context, vg *v1alpha1.VolumeGroup, previousName string) error {
+	if !r.NodeCapacityAnnotations && !r.NodeCapacityExtendedResources {
+		return nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.NodeName}, node); err != nil {
+		return fmt.Errorf("could not get node to publish capacity: %w", err)
+	}
+
+	publish := vg.Status.Name != "" && vg.Status.Allocatable != nil && vg.GetDeletionTimestamp().IsZero()
+	var stale []string
+	if previousName != "" && previousName != vg.Status.Name {
+		stale = append(stale, previousName)
+	}
+	if !publish && vg.Status.Name != "" {
+		stale = append(stale, vg.Status.Name)
+	}
+
+	if r.NodeCapacityAnnotations {
+		original := node.DeepCopy()
+		for _, name := range stale {
+			delete(node.Annotations, NodeCapacityAnnotationPrefix+nodeCapacityKey(name))
+		}
+		if publish {
+			if node.Annotations == nil {
+				node.Annotations = map[string]string{}
+			}
+			node.Annotations[NodeCapacityAnnotationPrefix+nodeCapacityKey(vg.Status.Name)] = vg.Status.Allocatable.String()
+		}
+		if !equality.Semantic.DeepEqual(original.Annotations, node.Annotations) {
+			if err := r.Patch(ctx, node, client.MergeFrom(original)); err != nil {
+				return fmt.Errorf("could not publish capacity in node annotations: %w", err)
+			}
+		}
+	}
+
+	if r.NodeCapacityExtendedResources {
+		original := node.DeepCopy()
+		for _, name := range stale {
+			delete(node.Status.Capacity, corev1.ResourceName(NodeCapacityResourcePrefix+nodeCapacityKey(name)))
+		}
+		if publish {
+			if node.Status.Capacity == nil {
+				node.Status.Capacity = corev1.ResourceList{}
+			}
+			node.Status.Capacity[corev1.ResourceName(NodeCapacityResourcePrefix+nodeCapacityKey(vg.Status.Name))] = *vg.Status.Allocatable
+		}
+		if !equality.Semantic.DeepEqual(original.Status.Capacity, node.Status.Capacity) {
+			if err := r.Status().Patch(ctx, node, client.MergeFrom(original)); err != nil {
+				return fmt.Errorf("could not publish capacity in node extended resources: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// nodeCapacityKey returns the name part of the annotation and the extended resource of the volume group.
+// lvm2 allows names that are no valid name part of a qualified name, e.g. names with "+" or longer than 63 characters.
+// Such names are encoded by replacing invalid characters, truncating the name and appending a hash of the name,
+// so that different volume groups never share a key.
+func nodeCapacityKey(name string) string {
+	if len(validation.IsQualifiedName(name)) == 0 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:nodeCapacityHashLength]
+
+	key := strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.') {
+			return r
+		}
+		return '-'
+	}, name)
+	key = key[:min(len(key), nodeCapacityKeyMaxLength-nodeCapacityHashLength-1)]
+	key = strings.TrimLeft(strings.TrimRight(key, "-_."), "-_.")
+	if key == "" {
+		return suffix
+	}
+	return key + "-" + suffix
+}
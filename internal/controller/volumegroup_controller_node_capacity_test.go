@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNodeCapacityKey(t *testing.T) {
+	if key := nodeCapacityKey("vg1"); key != "vg1" {
+		t.Errorf("expected valid name to be kept, got %s", key)
+	}
+
+	long := strings.Repeat("a", 127)
+	for _, name := range []string{"vg+1", "-vg", "+", long, long[:126] + "+"} {
+		key := nodeCapacityKey(name)
+		if errs := validation.IsQualifiedName(NodeCapacityAnnotationPrefix + key); len(errs) > 0 {
+			t.Errorf("expected valid key for %q, got %q: %v", name, key, errs)
+		}
+	}
+
+	if nodeCapacityKey("vg+1") == nodeCapacityKey("vg-1") || nodeCapacityKey(long) == nodeCapacityKey(long[:126]+"+") {
+		t.Errorf("expected different names to get different keys")
+	}
+}
+
+func TestPublishNodeCapacity(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().
+		WithObjects(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}).
+		WithStatusSubresource(&corev1.Node{}).
+		Build()
+	r := &VolumeGroupReconciler{Client: c, NodeName: "node", NodeCapacityAnnotations: true, NodeCapacityExtendedResources: true}
+
+	allocatable := resource.MustParse("10Gi")
+	vg := &v1alpha1.VolumeGroup{Status: v1alpha1.VolumeGroupStatus{Name: "vg+1", Allocatable: &allocatable}}
+	published := func(name string) (string, *resource.Quantity) {
+		node := &corev1.Node{}
+		if err := c.Get(ctx, client.ObjectKey{Name: "node"}, node); err != nil {
+			t.Fatal(err)
+		}
+		annotation := node.Annotations[NodeCapacityAnnotationPrefix+nodeCapacityKey(name)]
+		if capacity, ok := node.Status.Capacity[corev1.ResourceName(NodeCapacityResourcePrefix+nodeCapacityKey(name))]; ok {
+			return annotation, &capacity
+		}
+		return annotation, nil
+	}
+
+	if err := r.publishNodeCapacity(ctx, vg, ""); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, capacity := published("vg+1"); annotation != "10Gi" || capacity == nil || capacity.Cmp(allocatable) != 0 {
+		t.Errorf("expected capacity 10Gi to be published, got annotation %q and extended resource %v", annotation, capacity)
+	}
+
+	// The capacity published under the previous name is removed on rename.
+	vg.Status.Name = "vg2"
+	if err := r.publishNodeCapacity(ctx, vg, "vg+1"); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, capacity := published("vg+1"); annotation != "" || capacity != nil {
+		t.Errorf("expected capacity of the previous name to be removed, got annotation %q and extended resource %v", annotation, capacity)
+	}
+	if annotation, capacity := published("vg2"); annotation != "10Gi" || capacity == nil {
+		t.Errorf("expected capacity 10Gi to be published, got annotation %q and extended resource %v", annotation, capacity)
+	}
+
+	// The capacity is removed once the volume group is being deleted.
+	vg.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	if err := r.publishNodeCapacity(ctx, vg, "vg2"); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, capacity := published("vg2"); annotation != "" || capacity != nil {
+		t.Errorf("expected capacity to be removed, got annotation %q and extended resource %v", annotation, capacity)
+	}
+}
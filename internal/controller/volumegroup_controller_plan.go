@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/utils"
+)
+
+// PlanOnlyAnnotation puts a VolumeGroup into plan mode if set to "true".
+// In plan mode, the lvm2 operations that would be run to sync the volume group with the spec are written to
+// VolumeGroupStatus.PlannedOperations instead of being run on the node. Removing the annotation applies the plan.
+// The removal of the volume group on deletion of the VolumeGroup is not affected by plan mode.
+const PlanOnlyAnnotation = "topolvm.io/plan-only"
+
+// isPlanOnly returns true if the VolumeGroup is in plan mode.
+func isPlanOnly(vg *v1alpha1.VolumeGroup) bool {
+	return vg.GetAnnotations()[PlanOnlyAnnotation] == "true"
+}
+
+// planDrift converts drifts into the operations that would correct them.
+func planDrift(drifts []v1alpha1.VolumeGroupDrift) []v1alpha1.PlannedOperation {
+	return utils.Map(drifts, func(d v1alpha1.VolumeGroupDrift) v1alpha1.PlannedOperation {
+		return v1alpha1.PlannedOperation{Field: d.Field, Operation: d.Operation}
+	})
+}
+
+// planCreation returns the operation that would create the volume group with the given options.
+func planCreation(opts *lvm2go.VGCreateOptions) v1alpha1.PlannedOperation {
+	pvs := utils.Map(opts.PhysicalVolumeNames, func(pv lvm2go.PhysicalVolumeName) string {
+		return string(pv)
+	})
+	return v1alpha1.PlannedOperation{
+		Operation: fmt.Sprintf("vgcreate %s %s", opts.VolumeGroupName, strings.Join(pvs, " ")),
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// diffMetadataProfile calculates the difference between the desired and the installed content of the metadata profile
+// and between the desired and the attached metadata profile of the volume group.
+func (r *VolumeGroupReconciler) diffMetadataProfile(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	profile := vg.Spec.MetadataProfile
+	if profile == nil {
+		return nil, nil
+	}
+
+	var drifts []drift
+	if profile.ConfigMap != nil {
+		installDrift, err := r.diffMetadataProfileContent(ctx, vg, profile)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, installDrift...)
+	}
+
+	actual, err := r.metadataProfile(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get metadata profile to sync spec: %w", err)
+	}
+	if actual == profile.Name {
+		return drifts, nil
+	}
+
+	return append(drifts, drift{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "metadataProfile.name",
+			Desired:   profile.Name,
+			Actual:    actual,
+			Operation: fmt.Sprintf("vgchange %s --metadataprofile %s", lvm.Name, profile.Name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --metadataprofile", func(ctx context.Context) error {
+				return lvmcmd.ChangeMetadataProfile(ctx, string(lvm.Name), profile.Name)
+			})
+		},
+	}), nil
+}
+
+// diffMetadataProfileContent calculates the difference between the content of the metadata profile in the ConfigMap
+// and the profile installed on the node.
+func (r *VolumeGroupReconciler) diffMetadataProfileContent(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	profile *v1alpha1.MetadataProfile,
+) ([]drift, error) {
+	desired, err := r.loadMetadataProfile(ctx, vg, profile.ConfigMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var dir string
+	var installed []byte
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvmconfig", func(ctx context.Context) (err error) {
+		if dir, err = lvmcmd.ProfileDir(ctx); err != nil {
+			return err
+		}
+		installed, err = lvmcmd.InstalledProfile(ctx, dir, profile.Name)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get installed metadata profile to sync spec: %w", err)
+	}
+
+	if bytes.Equal(desired, installed) {
+		return nil, nil
+	}
+
+	actual := "installed"
+	if len(installed) == 0 {
+		actual = "missing"
+	}
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "metadataProfile.configMap",
+			Desired:   fmt.Sprintf("%s/%s", profile.ConfigMap.Name, profile.ConfigMap.Key),
+			Actual:    actual,
+			Operation: fmt.Sprintf("install %s/%s.profile", dir, profile.Name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "install profile", func(ctx context.Context) error {
+				return lvmcmd.InstallProfile(ctx, dir, profile.Name, desired)
+			})
+		},
+	}}, nil
+}
+
+// loadMetadataProfile returns the content of the metadata profile referenced in the namespace of the VolumeGroup.
+func (r *VolumeGroupReconciler) loadMetadataProfile(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	ref *v1alpha1.ProfileConfigMapReference,
+) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: vg.GetNamespace(), Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("could not get ConfigMap with metadata profile: %w", err)
+	}
+	if content, ok := cm.Data[ref.Key]; ok {
+		return []byte(content), nil
+	}
+	if content, ok := cm.BinaryData[ref.Key]; ok {
+		return content, nil
+	}
+	return nil, fmt.Errorf("ConfigMap %s does not contain the metadata profile key %s", ref.Name, ref.Key)
+}
+
+// metadataProfile returns the name of the metadata profile attached to the volume group, if any.
+func (r *VolumeGroupReconciler) metadataProfile(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (profile string, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		profile, err = lvmcmd.MetadataProfile(ctx, string(lvm.Name))
+		return
+	})
+	return
+}
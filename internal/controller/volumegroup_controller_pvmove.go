@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PhysicalVolumeMoveProgressInterval is the interval in which the progress of running pvmoves is refreshed.
+const PhysicalVolumeMoveProgressInterval = 5 * time.Second
+
+// ErrPhysicalVolumeMoveInProgress is returned while extents are still being moved off physical volumes
+// that should be removed from the volume group. It is not a failure, the volume group is reduced
+// once all moves have completed.
+var ErrPhysicalVolumeMoveInProgress = errors.New("physical volume move in progress")
+
+// movePhysicalVolumes moves all extents off the sources onto the destinations with pvmove in the background.
+// It returns nil once all sources are empty and can be reduced from the volume group, and
+// ErrPhysicalVolumeMoveInProgress as long as extents are still allocated on any source.
+func (r *VolumeGroupReconciler) movePhysicalVolumes(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	sources []lvm2go.PhysicalVolumeName,
+	destinations []lvm2go.PhysicalVolumeName,
+) error {
+	moves, err := lvmcmd.Moves(ctx, string(lvm.Name))
+	if err != nil {
+		return err
+	}
+
+	// Moves that were started by a previous instance of the controller are no longer polled
+	// after a restart, so they have to be resumed once.
+	if len(moves) > 0 && !r.movesResumed.Load() {
+		log.FromContext(ctx).Info("resuming interrupted physical volume moves", "count", len(moves))
+		if err := lvmcmd.ResumeMoves(ctx); err != nil {
+			return err
+		}
+		r.movesResumed.Store(true)
+	}
+
+	dests := utils.Map(destinations, func(pv lvm2go.PhysicalVolumeName) string {
+		return string(pv)
+	})
+
+	inProgress := false
+	for _, source := range sources {
+		done, err := r.movePhysicalVolume(ctx, vg, string(source), dests, moves)
+		if err != nil {
+			return err
+		}
+		if !done {
+			inProgress = true
+		}
+	}
+
+	if inProgress {
+		return ErrPhysicalVolumeMoveInProgress
+	}
+	return nil
+}
+
+// movePhysicalVolume refreshes the move status of a single physical volume and starts the next pvmove
+// if no move is active and there are still extents allocated on the physical volume.
+// It returns true once no extents are allocated on the physical volume anymore.
+func (r *VolumeGroupReconciler) movePhysicalVolume(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	source string,
+	destinations []string,
+	moves []lvmcmd.Move,
+) (bool, error) {
+	logger := log.FromContext(ctx).WithValues("source", source)
+
+	segments, err := lvmcmd.Segments(ctx, source)
+	if err != nil {
+		return false, err
+	}
+	var remaining int64
+	var allocated []lvmcmd.Segment
+	for _, segment := range segments {
+		if segment.Allocated() {
+			remaining += segment.Size
+			allocated = append(allocated, segment)
+		}
+	}
+
+	status := getOrCreatePhysicalVolumeMoveStatus(&vg.Status, source)
+	status.RemainingExtents = remaining
+	now := metav1.Now()
+
+	if idx := slices.IndexFunc(moves, func(move lvmcmd.Move) bool {
+		return move.Source == source
+	}); idx >= 0 {
+		if status.TotalExtents == 0 {
+			// The move was started before the status was recorded, e.g. by a previous controller instance.
+			status.TotalExtents = remaining
+			status.StartTime = now
+		}
+		status.Active = true
+		status.Percent = physicalVolumeMovePercent(status, moves[idx].CopyPercent)
+		logger.V(1).Info("physical volume move in progress", "percent", status.Percent)
+		return false, nil
+	}
+
+	if status.Active {
+		status.Active = false
+		status.CurrentMoveExtents = 0
+		status.LastCompletionTime = &now
+	}
+	status.Percent = physicalVolumeMovePercent(status, 0)
+
+	if remaining == 0 {
+		return true, nil
+	}
+
+	opts := vg.Spec.PhysicalVolumeMove
+	if opts != nil && opts.PauseBetweenMoves != nil && status.LastCompletionTime != nil &&
+		now.Before(&metav1.Time{Time: status.LastCompletionTime.Add(opts.PauseBetweenMoves.Duration)}) {
+		logger.V(1).Info("pausing before starting the next move")
+		return false, nil
+	}
+
+	// Move the first allocated segment, limited by the maximum extents per move.
+	start, size := allocated[0].Start, allocated[0].Size
+	if opts != nil && opts.MaximumExtentsPerMove != nil && *opts.MaximumExtentsPerMove > 0 {
+		size = min(size, *opts.MaximumExtentsPerMove)
+	}
+
+	logger.Info("starting physical volume move", "start", start, "extents", size, "destinations", destinations)
+	if err := lvmcmd.StartMove(ctx, source, start, start+size-1, destinations); err != nil {
+		return false, err
+	}
+
+	if status.TotalExtents == 0 {
+		status.TotalExtents = remaining
+		status.StartTime = now
+	}
+	status.Active = true
+	status.CurrentMoveExtents = size
+
+	return false, nil
+}
+
+// getOrCreatePhysicalVolumeMoveStatus returns the move status of the physical volume, creating it if it does not exist.
+func getOrCreatePhysicalVolumeMoveStatus(
+	status *v1alpha1.VolumeGroupStatus,
+	name string,
+) *v1alpha1.PhysicalVolumeMoveStatus {
+	for i := range status.PhysicalVolumeMoves {
+		if status.PhysicalVolumeMoves[i].Name == name {
+			return &status.PhysicalVolumeMoves[i]
+		}
+	}
+	status.PhysicalVolumeMoves = append(status.PhysicalVolumeMoves, v1alpha1.PhysicalVolumeMoveStatus{Name: name})
+	return &status.PhysicalVolumeMoves[len(status.PhysicalVolumeMoves)-1]
+}
+
+// prunePhysicalVolumeMoveStatus removes the move status of all physical volumes not contained in names
+// unless a move is still active for them.
+func prunePhysicalVolumeMoveStatus(status *v1alpha1.VolumeGroupStatus, names []lvm2go.PhysicalVolumeName) {
+	status.PhysicalVolumeMoves = slices.DeleteFunc(status.PhysicalVolumeMoves, func(move v1alpha1.PhysicalVolumeMoveStatus) bool {
+		return !move.Active && !slices.Contains(names, lvm2go.PhysicalVolumeName(move.Name))
+	})
+}
+
+// physicalVolumeMovePercent calculates the overall progress of moving all extents off a physical volume,
+// taking into account the progress of the currently active move.
+func physicalVolumeMovePercent(status *v1alpha1.PhysicalVolumeMoveStatus, copyPercent float64) string {
+	if status.TotalExtents == 0 {
+		return strconv.FormatFloat(0, 'f', 2, 64)
+	}
+	moved := float64(status.TotalExtents-status.RemainingExtents) +
+		float64(status.CurrentMoveExtents)*copyPercent/100
+	percent := min(max(moved/float64(status.TotalExtents)*100, 0), 100)
+	return strconv.FormatFloat(percent, 'f', 2, 64)
+}
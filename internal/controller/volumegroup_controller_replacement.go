@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// replacementStep is a step of the replacement of a missing physical volume.
+// run returns a message describing the outcome of the step, which is reported in the status.
+type replacementStep struct {
+	step      v1alpha1.PhysicalVolumeReplacementStep
+	operation string
+	run       func(ctx context.Context, status *v1alpha1.PhysicalVolumeReplacementStatus) (string, error)
+}
+
+// replacementStepOrder is the order of the steps of a replacement of a missing physical volume.
+var replacementStepOrder = []v1alpha1.PhysicalVolumeReplacementStep{
+	v1alpha1.PhysicalVolumeReplacementStepExtend,
+	v1alpha1.PhysicalVolumeReplacementStepRepair,
+	v1alpha1.PhysicalVolumeReplacementStepRemoveMissing,
+}
+
+// diffReplacements calculates the remaining steps of the replacements of missing physical volumes.
+// The steps are run one at a time across all replacements, so that the logical volumes are repaired onto every
+// replacement before the missing physical volumes are removed. A failed step is recorded in the status and
+// retried with the next sync.
+func (r *VolumeGroupReconciler) diffReplacements(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	prunePhysicalVolumeReplacementStatus(vg)
+	if len(vg.Spec.PhysicalVolumeReplacements) == 0 {
+		return nil, nil
+	}
+	name := string(lvm.Name)
+
+	var pvs []lvmcmd.PhysicalVolumeState
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = lvmcmd.PhysicalVolumeStates(ctx, name)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get physical volumes to replace: %w", err)
+	}
+
+	byStep := map[v1alpha1.PhysicalVolumeReplacementStep][]drift{}
+	for _, replacement := range vg.Spec.PhysicalVolumeReplacements {
+		current := v1alpha1.PhysicalVolumeReplacementStepExtend
+		if status := findPhysicalVolumeReplacementStatus(&vg.Status, replacement.MissingPhysicalVolume); status != nil {
+			current = status.Step
+		} else if !slices.ContainsFunc(pvs, func(pv lvmcmd.PhysicalVolumeState) bool {
+			return pv.Missing && pv.UUID == replacement.MissingPhysicalVolume
+		}) {
+			// Only missing physical volumes are replaced.
+			continue
+		}
+		if current == v1alpha1.PhysicalVolumeReplacementStepCompleted {
+			continue
+		}
+		steps, err := r.replacementSteps(ctx, vg, name, replacement, current, pvs)
+		if err != nil {
+			return nil, err
+		}
+		for step, d := range steps {
+			byStep[step] = append(byStep[step], d)
+		}
+	}
+
+	var drifts []drift
+	for _, step := range replacementStepOrder {
+		drifts = append(drifts, byStep[step]...)
+	}
+	return drifts, nil
+}
+
+// replacementSteps returns the remaining steps of the replacement by step, starting with the current step.
+// Devices of encrypted volume groups are prepared like any other device and replaced by their dm-crypt mappings.
+// The status of the replacement is only recorded once its first step is run.
+func (r *VolumeGroupReconciler) replacementSteps(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	name string,
+	replacement v1alpha1.PhysicalVolumeReplacement,
+	current v1alpha1.PhysicalVolumeReplacementStep,
+	pvs []lvmcmd.PhysicalVolumeState,
+) (map[v1alpha1.PhysicalVolumeReplacementStep]drift, error) {
+	uuid, device := replacement.MissingPhysicalVolume, replacement.Device
+	field := fmt.Sprintf("physicalVolumeReplacements[%s]", uuid)
+	actual := fmt.Sprintf("missing %s", uuid)
+
+	mappings, prepare, err := r.prepareEncryptedDevices(ctx, vg, []lvm2go.PhysicalVolumeName{lvm2go.PhysicalVolumeName(device)})
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare device %s to replace missing physical volume %s: %w", device, uuid, err)
+	}
+	pv := string(mappings[0])
+	extend := fmt.Sprintf("vgextend %s %s", name, pv)
+	for i := len(prepare) - 1; i >= 0; i-- {
+		extend = prepare[i].Operation + ", " + extend
+	}
+
+	extended := slices.ContainsFunc(pvs, func(state lvmcmd.PhysicalVolumeState) bool {
+		return state.Name == pv
+	})
+
+	reduceOpts := []lvm2go.VGReduceOption{lvm2go.VolumeGroupName(name), lvm2go.RemoveMissing(true)}
+	reduce := fmt.Sprintf("vgreduce %s --removemissing", name)
+	if replacement.Force {
+		reduceOpts = append(reduceOpts, lvm2go.Force(true))
+		reduce += " --force"
+	}
+
+	steps := []replacementStep{
+		{
+			step:      v1alpha1.PhysicalVolumeReplacementStepExtend,
+			operation: extend,
+			run: func(ctx context.Context, _ *v1alpha1.PhysicalVolumeReplacementStatus) (string, error) {
+				if extended {
+					return fmt.Sprintf("%s is already a physical volume of the volume group", pv), nil
+				}
+				for _, d := range prepare {
+					if err := d.correct(ctx); err != nil {
+						return "", err
+					}
+				}
+				return fmt.Sprintf("added %s to the volume group", pv), r.withTimeout(ctx, vg, OperationLong, "vgextend", func(ctx context.Context) error {
+					return r.LVM.VGExtend(ctx, lvm2go.VolumeGroupName(name), lvm2go.PhysicalVolumeNames{lvm2go.PhysicalVolumeName(pv)})
+				})
+			},
+		},
+		{
+			step:      v1alpha1.PhysicalVolumeReplacementStepRepair,
+			operation: fmt.Sprintf("lvconvert --repair %s/<partial raid and mirror volumes> %s", name, pv),
+			run: func(ctx context.Context, status *v1alpha1.PhysicalVolumeReplacementStatus) (string, error) {
+				return r.repairLogicalVolumes(ctx, vg, name, pv, status)
+			},
+		},
+		{
+			step:      v1alpha1.PhysicalVolumeReplacementStepRemoveMissing,
+			operation: reduce,
+			run: func(ctx context.Context, _ *v1alpha1.PhysicalVolumeReplacementStatus) (string, error) {
+				// vgreduce --removemissing removes all missing physical volumes and, with --force, the logical volumes
+				// on them, so it is only run once the logical volumes are repaired onto every replacement.
+				if pending := pendingPhysicalVolumeReplacements(vg, pvs); len(pending) > 0 {
+					return "", fmt.Errorf("refusing to remove missing physical volumes, as the missing physical volumes "+
+						"%s are not replaced yet", strings.Join(pending, " "))
+				}
+				return "removed the missing physical volume from the volume group", r.withTimeout(ctx, vg, OperationMutation, "vgreduce --removemissing", func(ctx context.Context) error {
+					return r.LVM.VGReduce(ctx, reduceOpts...)
+				})
+			},
+		},
+	}
+
+	idx := slices.IndexFunc(steps, func(s replacementStep) bool {
+		return s.step == current
+	})
+	if idx < 0 {
+		return nil, nil
+	}
+
+	drifts := make(map[v1alpha1.PhysicalVolumeReplacementStep]drift, len(steps)-idx)
+	for i, step := range steps[idx:] {
+		next := v1alpha1.PhysicalVolumeReplacementStepCompleted
+		if idx+i+1 < len(steps) {
+			next = steps[idx+i+1].step
+		}
+		drifts[step.step] = drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     field,
+				Desired:   fmt.Sprintf("replaced by %s", device),
+				Actual:    actual,
+				Operation: step.operation,
+			},
+			correct: func(ctx context.Context) error {
+				status := recordPhysicalVolumeReplacementStatus(vg, replacement)
+				message, err := step.run(ctx, status)
+				if err != nil {
+					status.Failed = true
+					status.Message = fmt.Sprintf("%s failed: %s", step.step, err.Error())
+					return fmt.Errorf("could not replace missing physical volume %s with %s: %w", uuid, device, err)
+				}
+				log.FromContext(ctx).Info("physical volume replacement step completed",
+					"missingPhysicalVolume", uuid, "device", device, "step", step.step)
+				status.Step = next
+				status.Failed = false
+				status.Message = message
+				status.LastTransitionTime = metav1.Now()
+				if next == v1alpha1.PhysicalVolumeReplacementStepCompleted {
+					vg.Status.MissingPhysicalVolumes = slices.DeleteFunc(vg.Status.MissingPhysicalVolumes,
+						func(missing v1alpha1.MissingPhysicalVolumeStatus) bool {
+							return missing.UUID == uuid
+						})
+				}
+				return nil
+			},
+		}
+	}
+	return drifts, nil
+}
+
+// repairLogicalVolumes repairs the partial RAID and mirror logical volumes onto the physical volume.
+// Partial logical volumes without redundancy cannot be repaired and are only reported.
+func (r *VolumeGroupReconciler) repairLogicalVolumes(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	name, pv string,
+	status *v1alpha1.PhysicalVolumeReplacementStatus,
+) (string, error) {
+	var partial []lvmcmd.PartialLogicalVolume
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvs", func(ctx context.Context) (err error) {
+		partial, err = lvmcmd.PartialLogicalVolumes(ctx, name)
+		return
+	}); err != nil {
+		return "", err
+	}
+
+	var unrepairable []string
+	for _, lv := range partial {
+		if !lv.Repairable() {
+			unrepairable = append(unrepairable, lv.Name)
+			continue
+		}
+		if err := r.withTimeout(ctx, vg, OperationLong, "lvconvert --repair", func(ctx context.Context) error {
+			return lvmcmd.RepairLogicalVolume(ctx, name, lv.Name, pv)
+		}); err != nil {
+			return "", err
+		}
+		if !slices.Contains(status.RepairedLogicalVolumes, lv.Name) {
+			status.RepairedLogicalVolumes = append(status.RepairedLogicalVolumes, lv.Name)
+		}
+	}
+
+	message := fmt.Sprintf("repaired %d logical volume(s)", len(status.RepairedLogicalVolumes))
+	if len(unrepairable) > 0 {
+		message += fmt.Sprintf(", logical volumes without redundancy cannot be repaired: %s",
+			strings.Join(unrepairable, " "))
+	}
+	return message, nil
+}
+
+// replacementInProgress returns true if any replacement of a missing physical volume has not completed yet.
+// Missing physical volumes are not removed due to DeviceLossSynchronizationPolicy while they are replaced.
+func replacementInProgress(vg *v1alpha1.VolumeGroup) bool {
+	for _, replacement := range vg.Spec.PhysicalVolumeReplacements {
+		status := findPhysicalVolumeReplacementStatus(&vg.Status, replacement.MissingPhysicalVolume)
+		if status != nil && status.Step != v1alpha1.PhysicalVolumeReplacementStepCompleted {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingPhysicalVolumeReplacements returns the UUIDs of the missing physical volumes without a replacement in the spec
+// or whose replacement has not repaired its logical volumes yet.
+func pendingPhysicalVolumeReplacements(vg *v1alpha1.VolumeGroup, pvs []lvmcmd.PhysicalVolumeState) []string {
+	var pending []string
+	for _, pv := range pvs {
+		if !pv.Missing {
+			continue
+		}
+		status := findPhysicalVolumeReplacementStatus(&vg.Status, pv.UUID)
+		if status == nil || status.Step == v1alpha1.PhysicalVolumeReplacementStepExtend ||
+			status.Step == v1alpha1.PhysicalVolumeReplacementStepRepair {
+			pending = append(pending, pv.UUID)
+		}
+	}
+	return pending
+}
+
+// recordPhysicalVolumeReplacementStatus returns the status of the replacement, which is recorded when it starts.
+func recordPhysicalVolumeReplacementStatus(
+	vg *v1alpha1.VolumeGroup,
+	replacement v1alpha1.PhysicalVolumeReplacement,
+) *v1alpha1.PhysicalVolumeReplacementStatus {
+	if status := findPhysicalVolumeReplacementStatus(&vg.Status, replacement.MissingPhysicalVolume); status != nil {
+		return status
+	}
+	now := metav1.Now()
+	vg.Status.PhysicalVolumeReplacements = append(vg.Status.PhysicalVolumeReplacements, v1alpha1.PhysicalVolumeReplacementStatus{
+		MissingPhysicalVolume: replacement.MissingPhysicalVolume,
+		Device:                replacement.Device,
+		Step:                  v1alpha1.PhysicalVolumeReplacementStepExtend,
+		StartTime:             now,
+		LastTransitionTime:    now,
+	})
+	return &vg.Status.PhysicalVolumeReplacements[len(vg.Status.PhysicalVolumeReplacements)-1]
+}
+
+func findPhysicalVolumeReplacementStatus(
+	status *v1alpha1.VolumeGroupStatus,
+	uuid string,
+) *v1alpha1.PhysicalVolumeReplacementStatus {
+	for i := range status.PhysicalVolumeReplacements {
+		if status.PhysicalVolumeReplacements[i].MissingPhysicalVolume == uuid {
+			return &status.PhysicalVolumeReplacements[i]
+		}
+	}
+	return nil
+}
+
+// prunePhysicalVolumeReplacementStatus removes the status of replacements that were removed from the spec.
+func prunePhysicalVolumeReplacementStatus(vg *v1alpha1.VolumeGroup) {
+	vg.Status.PhysicalVolumeReplacements = slices.DeleteFunc(vg.Status.PhysicalVolumeReplacements,
+		func(status v1alpha1.PhysicalVolumeReplacementStatus) bool {
+			return !slices.ContainsFunc(vg.Spec.PhysicalVolumeReplacements, func(replacement v1alpha1.PhysicalVolumeReplacement) bool {
+				return replacement.MissingPhysicalVolume == status.MissingPhysicalVolume
+			})
+		})
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+)
+
+func TestPendingPhysicalVolumeReplacements(t *testing.T) {
+	pvs := []lvmcmd.PhysicalVolumeState{
+		{Name: "/dev/sdb", UUID: "present"},
+		{Name: "[unknown]", UUID: "unreplaced", Missing: true},
+		{Name: "[unknown]", UUID: "extending", Missing: true},
+		{Name: "[unknown]", UUID: "repairing", Missing: true},
+		{Name: "[unknown]", UUID: "repaired", Missing: true},
+	}
+	vg := &v1alpha1.VolumeGroup{Status: v1alpha1.VolumeGroupStatus{
+		PhysicalVolumeReplacements: []v1alpha1.PhysicalVolumeReplacementStatus{
+			{MissingPhysicalVolume: "extending", Step: v1alpha1.PhysicalVolumeReplacementStepExtend},
+			{MissingPhysicalVolume: "repairing", Step: v1alpha1.PhysicalVolumeReplacementStepRepair},
+			{MissingPhysicalVolume: "repaired", Step: v1alpha1.PhysicalVolumeReplacementStepRemoveMissing},
+		},
+	}}
+
+	expected := []string{"unreplaced", "extending", "repairing"}
+	if pending := pendingPhysicalVolumeReplacements(vg, pvs); !slices.Equal(pending, expected) {
+		t.Errorf("expected pending replacements %v, got %v", expected, pending)
+	}
+}
+
+func TestRecordPhysicalVolumeReplacementStatus(t *testing.T) {
+	vg := &v1alpha1.VolumeGroup{}
+	replacement := v1alpha1.PhysicalVolumeReplacement{MissingPhysicalVolume: "missing", Device: "/dev/sdc"}
+
+	status := recordPhysicalVolumeReplacementStatus(vg, replacement)
+	if status.Step != v1alpha1.PhysicalVolumeReplacementStepExtend || status.Device != "/dev/sdc" || status.StartTime.IsZero() {
+		t.Errorf("expected replacement to start with %s, got %v", v1alpha1.PhysicalVolumeReplacementStepExtend, status)
+	}
+	status.Step = v1alpha1.PhysicalVolumeReplacementStepRepair
+
+	if recorded := recordPhysicalVolumeReplacementStatus(vg, replacement); recorded.Step != v1alpha1.PhysicalVolumeReplacementStepRepair ||
+		len(vg.Status.PhysicalVolumeReplacements) != 1 {
+		t.Errorf("expected the recorded replacement to be kept, got %v", vg.Status.PhysicalVolumeReplacements)
+	}
+}
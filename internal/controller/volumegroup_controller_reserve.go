@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// syncAllocatable sets the allocatable capacity of the volume group from its free and reserved capacity
+// and whether the reserve is violated.
+func syncAllocatable(vg *v1alpha1.VolumeGroup) {
+	if vg.Status.Free == nil {
+		return
+	}
+	free := vg.Status.Free.Value()
+	reserved := reservedCapacity(vg)
+
+	vg.Status.Allocatable = resource.NewQuantity(max(free-reserved, 0), resource.BinarySI)
+
+	switch {
+	case vg.Spec.ReservedCapacity == nil:
+		meta.RemoveStatusCondition(&vg.Status.Conditions, ConditionTypeReserveViolated)
+	case free < reserved:
+		SetReserveViolated(&vg.Status.Conditions, vg.GetGeneration(),
+			resource.NewQuantity(free, resource.BinarySI), resource.NewQuantity(reserved, resource.BinarySI))
+	default:
+		SetReserveAvailable(&vg.Status.Conditions, vg.GetGeneration())
+	}
+}
+
+// reservedCapacity returns the capacity in bytes reserved in the volume group by the spec.
+// A reserve in percent is calculated from the size of the volume group in the status.
+func reservedCapacity(vg *v1alpha1.VolumeGroup) int64 {
+	reserve := vg.Spec.ReservedCapacity
+	switch {
+	case reserve == nil:
+		return 0
+	case reserve.Size != nil:
+		return reserve.Size.Value()
+	case reserve.Percent != nil && vg.Status.Size != nil:
+		return vg.Status.Size.Value() * int64(*reserve.Percent) / 100
+	}
+	return 0
+}
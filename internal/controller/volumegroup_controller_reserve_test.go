@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReservedCapacity(t *testing.T) {
+	size := resource.MustParse("10Gi")
+	reserve := resource.MustParse("1Gi")
+	for name, tc := range map[string]struct {
+		reserve  *v1alpha1.ReservedCapacity
+		size     *resource.Quantity
+		expected int64
+	}{
+		"none":                 {expected: 0},
+		"size":                 {reserve: &v1alpha1.ReservedCapacity{Size: &reserve}, expected: 1 << 30},
+		"percent":              {reserve: &v1alpha1.ReservedCapacity{Percent: ptr[int32](10)}, size: &size, expected: 1 << 30},
+		"percent without size": {reserve: &v1alpha1.ReservedCapacity{Percent: ptr[int32](10)}, expected: 0},
+	} {
+		vg := &v1alpha1.VolumeGroup{
+			Spec:   v1alpha1.VolumeGroupSpec{ReservedCapacity: tc.reserve},
+			Status: v1alpha1.VolumeGroupStatus{Size: tc.size},
+		}
+		if reserved := reservedCapacity(vg); reserved != tc.expected {
+			t.Errorf("%s: expected %d bytes reserved, got %d", name, tc.expected, reserved)
+		}
+	}
+}
+
+func TestSyncAllocatable(t *testing.T) {
+	size := resource.MustParse("10Gi")
+	reserve := resource.MustParse("2Gi")
+	for name, tc := range map[string]struct {
+		reserve     *v1alpha1.ReservedCapacity
+		free        string
+		allocatable string
+		violated    *metav1.ConditionStatus
+	}{
+		"without reserve":  {free: "4Gi", allocatable: "4Gi"},
+		"reserve kept":     {reserve: &v1alpha1.ReservedCapacity{Size: &reserve}, free: "4Gi", allocatable: "2Gi", violated: ptr(metav1.ConditionFalse)},
+		"reserve violated": {reserve: &v1alpha1.ReservedCapacity{Size: &reserve}, free: "1Gi", allocatable: "0", violated: ptr(metav1.ConditionTrue)},
+	} {
+		free := resource.MustParse(tc.free)
+		vg := &v1alpha1.VolumeGroup{
+			Spec:   v1alpha1.VolumeGroupSpec{ReservedCapacity: tc.reserve},
+			Status: v1alpha1.VolumeGroupStatus{Size: &size, Free: &free},
+		}
+		syncAllocatable(vg)
+
+		if expected := resource.MustParse(tc.allocatable); vg.Status.Allocatable == nil || vg.Status.Allocatable.Cmp(expected) != 0 {
+			t.Errorf("%s: expected %s allocatable, got %v", name, tc.allocatable, vg.Status.Allocatable)
+		}
+		condition := meta.FindStatusCondition(vg.Status.Conditions, ConditionTypeReserveViolated)
+		switch {
+		case tc.violated == nil && condition != nil:
+			t.Errorf("%s: expected no %s condition, got %v", name, ConditionTypeReserveViolated, condition)
+		case tc.violated != nil && (condition == nil || condition.Status != *tc.violated):
+			t.Errorf("%s: expected %s condition with status %s, got %v", name, ConditionTypeReserveViolated, *tc.violated, condition)
+		}
+	}
+
+	vg := &v1alpha1.VolumeGroup{}
+	if syncAllocatable(vg); vg.Status.Allocatable != nil {
+		t.Errorf("expected no allocatable capacity without free capacity in the status")
+	}
+}
@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SharedLockStopInterval is the interval in which the removal of a shared volume group checks
+// whether the lockspace was stopped on all other nodes.
+const SharedLockStopInterval = 5 * time.Second
+
+// lockTypeNone is the lock type of volume groups that are not shared.
+const lockTypeNone = "none"
+
+// isSharedWith returns true if the volume group is shared with the node in addition to its NodeName.
+func isSharedWith(vg *v1alpha1.VolumeGroup, node string) bool {
+	return vg.Spec.Shared != nil && slices.Contains(vg.Spec.Shared.NodeNames, node)
+}
+
+func convertToLockType(shared *v1alpha1.SharedVolumeGroup) string {
+	if shared == nil {
+		return lockTypeNone
+	}
+	return strings.ToLower(string(shared.LockType))
+}
+
+// diffLockType calculates the difference between the desired and the actual lock type of the volume group.
+// A volume group is always created as a local volume group and converted into a shared volume group afterwards.
+func (r *VolumeGroupReconciler) diffLockType(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	actual, err := r.lockType(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get lock type to sync spec: %w", err)
+	}
+
+	desired := convertToLockType(vg.Spec.Shared)
+	if actual == desired {
+		return nil, nil
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "shared",
+			Desired:   desired,
+			Actual:    actual,
+			Operation: fmt.Sprintf("vgchange %s --locktype %s", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --locktype", func(ctx context.Context) error {
+				return lvmcmd.ChangeLockType(ctx, string(lvm.Name), desired)
+			})
+		},
+	}}, nil
+}
+
+// diffLockStart calculates whether the lockspace of a shared volume group has to be started on the node.
+func (r *VolumeGroupReconciler) diffLockStart(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if vg.Spec.Shared == nil {
+		return nil, nil
+	}
+
+	started, err := r.lockStarted(ctx, vg, string(lvm.Name))
+	if err != nil {
+		return nil, fmt.Errorf("could not get lockspace to sync spec: %w", err)
+	}
+	if started {
+		return nil, nil
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "shared",
+			Desired:   "started",
+			Actual:    "stopped",
+			Operation: fmt.Sprintf("vgchange %s --lockstart", lvm.Name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --lockstart", func(ctx context.Context) error {
+				return lvmcmd.StartLock(ctx, string(lvm.Name))
+			})
+		},
+	}}, nil
+}
+
+// reconcileSharedNode starts the lockspace of a shared volume group on a node other than its NodeName,
+// and stops it once the node is removed from Shared.NodeNames or the VolumeGroup is deleted.
+// The volume group itself is only changed on its NodeName.
+func (r *VolumeGroupReconciler) reconcileSharedNode(ctx context.Context, vg *v1alpha1.VolumeGroup) (ctrl.Result, error) {
+	name := string(getNameOnNode(vg))
+	logger := log.FromContext(ctx).WithValues("node", r.NodeName, "lvm_name", name)
+
+	if !isSharedWith(vg, r.NodeName) || !vg.GetDeletionTimestamp().IsZero() {
+		if getLockStatus(&vg.Status, r.NodeName) == nil {
+			return ctrl.Result{}, nil
+		}
+		logger.Info("stopping lockspace of shared volume group on node")
+		if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange --lockstop", func(ctx context.Context) error {
+			return lvmcmd.StopLock(ctx, name)
+		}); err != nil {
+			setLockStatus(&vg.Status, r.NodeName, true, err)
+			return ctrl.Result{}, classifyError(ctx, errors.Join(err, r.Client.Status().Update(ctx, vg)))
+		}
+		removeLockStatus(&vg.Status, r.NodeName)
+		return ctrl.Result{}, r.Client.Status().Update(ctx, vg)
+	}
+
+	if vg.Status.LockType == "" || vg.Status.LockType == lockTypeNone {
+		logger.V(1).Info("waiting for volume group to be converted into a shared volume group on its node")
+		return ctrl.Result{}, nil
+	}
+
+	// Starting the lockspace changes the node, so it is neither done in plan mode nor with DriftPolicyReport.
+	started, err := r.lockStarted(ctx, vg, name)
+	if err == nil && !started && !isPlanOnly(vg) && vg.Spec.DriftPolicy != v1alpha1.DriftPolicyReport {
+		logger.Info("starting lockspace of shared volume group on node")
+		err = r.withTimeout(ctx, vg, OperationMutation, "vgchange --lockstart", func(ctx context.Context) error {
+			return lvmcmd.StartLock(ctx, name)
+		})
+		started = err == nil
+	}
+	setLockStatus(&vg.Status, r.NodeName, started, err)
+
+	if err := errors.Join(err, r.Client.Status().Update(ctx, vg)); err != nil {
+		return ctrl.Result{}, classifyError(ctx, err)
+	}
+	return ctrl.Result{RequeueAfter: r.syncInterval(vg)}, nil
+}
+
+// nodesWithStartedLock returns the nodes other than the given node that report a started lockspace.
+func nodesWithStartedLock(status *v1alpha1.VolumeGroupStatus, node string) []string {
+	var nodes []string
+	for _, lock := range status.Locks {
+		if lock.NodeName != node && lock.Started {
+			nodes = append(nodes, lock.NodeName)
+		}
+	}
+	return nodes
+}
+
+// lockType returns the lock type of the volume group on the node.
+func (r *VolumeGroupReconciler) lockType(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (lockType string, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "vgs", func(ctx context.Context) (err error) {
+		lockType, err = lvmcmd.LockType(ctx, string(lvm.Name))
+		return
+	})
+	return
+}
+
+// lockStarted returns true if the lockspace of the volume group is started on the node.
+func (r *VolumeGroupReconciler) lockStarted(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	name string,
+) (started bool, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "lvmlockctl --info", func(ctx context.Context) (err error) {
+		started, err = lvmcmd.LockStarted(ctx, name)
+		return
+	})
+	return
+}
+
+func getLockStatus(status *v1alpha1.VolumeGroupStatus, node string) *v1alpha1.NodeLockStatus {
+	for i := range status.Locks {
+		if status.Locks[i].NodeName == node {
+			return &status.Locks[i]
+		}
+	}
+	return nil
+}
+
+// setLockStatus records the state of the lockspace on the node, updating the transition time if it changed.
+func setLockStatus(status *v1alpha1.VolumeGroupStatus, node string, started bool, err error) {
+	lock := getLockStatus(status, node)
+	if lock == nil {
+		status.Locks = append(status.Locks, v1alpha1.NodeLockStatus{
+			NodeName:           node,
+			Started:            started,
+			LastTransitionTime: metav1.Now(),
+		})
+		lock = &status.Locks[len(status.Locks)-1]
+	} else if lock.Started != started {
+		lock.Started = started
+		lock.LastTransitionTime = metav1.Now()
+	}
+	lock.Message = ""
+	if err != nil {
+		lock.Message = err.Error()
+	}
+}
+
+func removeLockStatus(status *v1alpha1.VolumeGroupStatus, node string) {
+	status.Locks = slices.DeleteFunc(status.Locks, func(lock v1alpha1.NodeLockStatus) bool {
+		return lock.NodeName == node
+	})
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StorageCapacityVolumeGroupLabel is the label of a CSIStorageCapacity with the UID of the VolumeGroup it was published for.
+	StorageCapacityVolumeGroupLabel = "topovgm.topolvm.io/volumegroup-uid"
+	// topoLVMDeviceClassParameter is the StorageClass parameter of TopoLVM selecting the device class.
+	topoLVMDeviceClassParameter = "topolvm.io/device-class"
+	// storageCapacityGenerateName is the prefix of the generated names of CSIStorageCapacity objects.
+	storageCapacityGenerateName = "topovgm-"
+)
+
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=csistoragecapacities,verbs=get;list;watch;create;update;patch;delete
+
+// publishStorageCapacity publishes the allocatable capacity of the volume group in a CSIStorageCapacity
+// for each matching StorageClass and removes the CSIStorageCapacity objects of StorageClasses no longer matching.
+// Once the VolumeGroup is deleted, its CSIStorageCapacity objects are garbage collected through their owner reference.
+func (r *VolumeGroupReconciler) publishStorageCapacity(ctx context.Context, vg *v1alpha1.VolumeGroup) error {
+	publishing := vg.Spec.CSIStorageCapacity
+	if vg.Status.Allocatable == nil || !vg.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	published := &storagev1.CSIStorageCapacityList{}
+	if err := r.List(ctx, published, client.InNamespace(vg.GetNamespace()),
+		client.MatchingLabels{StorageCapacityVolumeGroupLabel: string(vg.GetUID())}); err != nil {
+		return fmt.Errorf("could not list published storage capacities: %w", err)
+	}
+
+	matching := map[string]bool{}
+	if publishing != nil {
+		classes := &storagev1.StorageClassList{}
+		if err := r.List(ctx, classes); err != nil {
+			return fmt.Errorf("could not list storage classes to publish storage capacity: %w", err)
+		}
+		for _, class := range classes.Items {
+			if class.Provisioner == publishing.Provisioner && matchesStorageClassParameters(vg, class.Parameters) {
+				matching[class.Name] = true
+			}
+		}
+	}
+
+	// The objects are found by their label and StorageClass, as names derived from both could collide or exceed
+	// the maximum length of a name. Duplicates, e.g. created before the cache observed an earlier creation, are removed.
+	var errs []error
+	byClass := map[string]*storagev1.CSIStorageCapacity{}
+	for i := range published.Items {
+		capacity := &published.Items[i]
+		if !matching[capacity.StorageClassName] || byClass[capacity.StorageClassName] != nil {
+			errs = append(errs, client.IgnoreNotFound(r.Delete(ctx, capacity)))
+			continue
+		}
+		byClass[capacity.StorageClassName] = capacity
+	}
+
+	for class := range matching {
+		topology := &metav1.LabelSelector{
+			MatchLabels: map[string]string{publishing.TopologyKey: vg.Spec.NodeName},
+		}
+		capacity := byClass[class]
+		if capacity != nil && !equality.Semantic.DeepEqual(capacity.NodeTopology, topology) {
+			// The topology segment of a CSIStorageCapacity is immutable.
+			errs = append(errs, client.IgnoreNotFound(r.Delete(ctx, capacity)))
+			capacity = nil
+		}
+
+		if capacity == nil {
+			capacity = &storagev1.CSIStorageCapacity{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:    vg.GetNamespace(),
+					GenerateName: storageCapacityGenerateName,
+					Labels:       map[string]string{StorageCapacityVolumeGroupLabel: string(vg.GetUID())},
+				},
+				StorageClassName: class,
+				NodeTopology:     topology,
+				Capacity:         vg.Status.Allocatable,
+			}
+			if err := ctrl.SetControllerReference(vg, capacity, r.Scheme); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, r.Create(ctx, capacity))
+			continue
+		}
+
+		if capacity.Capacity == nil || capacity.Capacity.Cmp(*vg.Status.Allocatable) != 0 {
+			patch := client.MergeFrom(capacity.DeepCopy())
+			capacity.Capacity = vg.Status.Allocatable
+			errs = append(errs, client.IgnoreNotFound(r.Patch(ctx, capacity, patch)))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("could not publish storage capacity: %w", err)
+	}
+	return nil
+}
+
+// matchesStorageClassParameters checks whether the parameters of a StorageClass match the volume group.
+// Without parameters to match, no StorageClass matches.
+func matchesStorageClassParameters(vg *v1alpha1.VolumeGroup, parameters map[string]string) bool {
+	match := vg.Spec.CSIStorageCapacity.MatchParameters
+	if len(match) == 0 && vg.Spec.TopoLVM != nil {
+		match = map[string]string{topoLVMDeviceClassParameter: vg.Spec.TopoLVM.Name}
+	}
+	if len(match) == 0 {
+		return false
+	}
+	for key, value := range match {
+		if parameters[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMatchesStorageClassParameters(t *testing.T) {
+	for name, tc := range map[string]struct {
+		match      map[string]string
+		topolvm    *v1alpha1.TopoLVMDeviceClass
+		parameters map[string]string
+		expected   bool
+	}{
+		"no parameters to match": {
+			parameters: map[string]string{"foo": "bar"},
+		},
+		"device class of topolvm": {
+			topolvm:    &v1alpha1.TopoLVMDeviceClass{Name: "ssd"},
+			parameters: map[string]string{topoLVMDeviceClassParameter: "ssd"},
+			expected:   true,
+		},
+		"other device class of topolvm": {
+			topolvm:    &v1alpha1.TopoLVMDeviceClass{Name: "ssd"},
+			parameters: map[string]string{topoLVMDeviceClassParameter: "hdd"},
+		},
+		"match parameters": {
+			match:      map[string]string{"foo": "bar"},
+			topolvm:    &v1alpha1.TopoLVMDeviceClass{Name: "ssd"},
+			parameters: map[string]string{"foo": "bar", "other": "value"},
+			expected:   true,
+		},
+		"missing match parameter": {
+			match:      map[string]string{"foo": "bar", "baz": "qux"},
+			parameters: map[string]string{"foo": "bar"},
+		},
+	} {
+		vg := &v1alpha1.VolumeGroup{Spec: v1alpha1.VolumeGroupSpec{
+			CSIStorageCapacity: &v1alpha1.CSIStorageCapacityPublishing{MatchParameters: tc.match},
+			TopoLVM:            tc.topolvm,
+		}}
+		if matches := matchesStorageClassParameters(vg, tc.parameters); matches != tc.expected {
+			t.Errorf("%s: expected match %t, got %t", name, tc.expected, matches)
+		}
+	}
+}
+
+func TestPublishStorageCapacity(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, v1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	allocatable := resource.MustParse("10Gi")
+	vg := &v1alpha1.VolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "vg", Namespace: "default", UID: "uid"},
+		Spec: v1alpha1.VolumeGroupSpec{
+			NodeName: "node",
+			CSIStorageCapacity: &v1alpha1.CSIStorageCapacityPublishing{
+				Provisioner: "topolvm.io",
+				TopologyKey: "topology.topolvm.io/node",
+			},
+			TopoLVM: &v1alpha1.TopoLVMDeviceClass{Name: "ssd"},
+		},
+		Status: v1alpha1.VolumeGroupStatus{Allocatable: &allocatable},
+	}
+	class := func(name, provisioner, deviceClass string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: provisioner,
+			Parameters:  map[string]string{topoLVMDeviceClassParameter: deviceClass},
+		}
+	}
+	stale := &storagev1.CSIStorageCapacity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stale",
+			Namespace: "default",
+			Labels:    map[string]string{StorageCapacityVolumeGroupLabel: "uid"},
+		},
+		StorageClassName: "hdd",
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		class("ssd", "topolvm.io", "ssd"),
+		class("hdd", "topolvm.io", "hdd"),
+		class("other", "other.io", "ssd"),
+		stale,
+	).Build()
+	r := &VolumeGroupReconciler{Client: c, Scheme: scheme}
+
+	capacities := func() []storagev1.CSIStorageCapacity {
+		list := &storagev1.CSIStorageCapacityList{}
+		if err := c.List(ctx, list, client.InNamespace("default")); err != nil {
+			t.Fatal(err)
+		}
+		return list.Items
+	}
+
+	if err := r.publishStorageCapacity(ctx, vg); err != nil {
+		t.Fatal(err)
+	}
+	published := capacities()
+	if len(published) != 1 {
+		t.Fatalf("expected a single CSIStorageCapacity, got %d", len(published))
+	}
+	if published[0].StorageClassName != "ssd" {
+		t.Errorf("expected capacity for StorageClass ssd, got %s", published[0].StorageClassName)
+	}
+	if published[0].Capacity.Cmp(allocatable) != 0 {
+		t.Errorf("expected capacity %s, got %s", allocatable.String(), published[0].Capacity.String())
+	}
+	if node := published[0].NodeTopology.MatchLabels["topology.topolvm.io/node"]; node != "node" {
+		t.Errorf("expected topology of node node, got %q", node)
+	}
+
+	// A changed capacity is patched in place.
+	allocatable = resource.MustParse("5Gi")
+	if err := r.publishStorageCapacity(ctx, vg); err != nil {
+		t.Fatal(err)
+	}
+	updated := capacities()
+	if len(updated) != 1 || updated[0].Name != published[0].Name {
+		t.Fatalf("expected CSIStorageCapacity %s to be updated, got %v", published[0].Name, updated)
+	}
+	if updated[0].Capacity.Cmp(allocatable) != 0 {
+		t.Errorf("expected capacity %s, got %s", allocatable.String(), updated[0].Capacity.String())
+	}
+
+	// A changed topology recreates the object, as the topology is immutable.
+	vg.Spec.NodeName = "other-node"
+	if err := r.publishStorageCapacity(ctx, vg); err != nil {
+		t.Fatal(err)
+	}
+	recreated := capacities()
+	if len(recreated) != 1 || recreated[0].Name == published[0].Name {
+		t.Fatalf("expected CSIStorageCapacity %s to be recreated, got %v", published[0].Name, recreated)
+	}
+
+	// Without publishing, all objects are removed.
+	vg.Spec.CSIStorageCapacity = nil
+	if err := r.publishStorageCapacity(ctx, vg); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := capacities(); len(remaining) != 0 {
+		t.Errorf("expected no CSIStorageCapacity, got %d", len(remaining))
+	}
+}
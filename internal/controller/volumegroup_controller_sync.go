@@ -4,15 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jakobmoellerdev/lvm2go"
 	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
 	"github.com/topolvm/topovgm/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// drift is a difference between the spec and the volume group on the node, together with the operation correcting it.
+type drift struct {
+	v1alpha1.VolumeGroupDrift
+	correct func(ctx context.Context) error
+}
+
+// differ computes the differences between the spec and the volume group on the node without changing the node.
+// The drifts of a differ are corrected in order, and correction of a differ stops at its first failed drift.
+type differ func(ctx context.Context, vg *v1alpha1.VolumeGroup, lvm *lvm2go.VolumeGroup) ([]drift, error)
+
 // sync synchronizes the desired state of the volume group with the actual state from lvm2.
+// Differences are only corrected with DriftPolicyCorrect, with DriftPolicyReport they are reported in the status.
+// In plan mode, the operations correcting the differences are written to the status instead of being run.
 func (r *VolumeGroupReconciler) sync(
 	ctx context.Context,
 	vg *v1alpha1.VolumeGroup,
@@ -20,13 +36,31 @@ func (r *VolumeGroupReconciler) sync(
 ) error {
 	SetSyncedOnHostDefault(&vg.Status.Conditions, vg.GetGeneration())
 
-	syncers := []func(context.Context, *v1alpha1.VolumeGroup, *lvm2go.VolumeGroup) error{
-		r.syncTags,
-		r.syncPVs,
-		r.syncMaximumVolumes,
-		r.syncAllocationPolicy,
-		r.syncAutoActivation,
-		r.syncName,
+	differs := []differ{
+		r.diffReplacements,
+		r.diffLockType,
+		r.diffLockStart,
+		r.diffSystemID,
+		r.diffTags,
+		r.diffPVs,
+		r.diffPVTags,
+		r.diffEncryptionKey,
+		r.diffDeviceHealth,
+		r.diffMaximumVolumes,
+		r.diffMetadataCopies,
+		r.diffMetadataIgnore,
+		r.diffMetadataProfile,
+		r.diffAllocationPolicy,
+		r.diffAutoActivation,
+		r.diffThinPools,
+		r.diffCache,
+		r.diffActivation,
+		r.diffName,
+	}
+
+	if vg.Spec.Exported || isExported(lvmvg) {
+		// An exported volume group cannot be changed, so it is only exported or imported.
+		differs = []differ{r.diffExport}
 	}
 
 	logger := log.FromContext(ctx).WithValues("vg", vg.Name)
@@ -37,9 +71,32 @@ func (r *VolumeGroupReconciler) sync(
 		logger.V(1).Info("finished syncing volume group", "duration", time.Since(start))
 	}()
 
-	errs := make([]error, 0, len(syncers))
-	for _, sync := range syncers {
-		errs = append(errs, sync(ctx, vg, lvmvg))
+	report := vg.Spec.DriftPolicy == v1alpha1.DriftPolicyReport
+	plan := isPlanOnly(vg)
+
+	errs := make([]error, 0, len(differs))
+	var drifts []v1alpha1.VolumeGroupDrift
+	moving := false
+	for _, diff := range differs {
+		found, err := diff(ctx, vg, lvmvg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, d := range found {
+			drifts = append(drifts, d.VolumeGroupDrift)
+			if report || plan {
+				continue
+			}
+			logger.Info("correcting drift", "field", d.Field, "operation", d.Operation)
+			if err := d.correct(ctx); errors.Is(err, ErrPhysicalVolumeMoveInProgress) {
+				moving = true
+				break
+			} else if err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
 	}
 	err := errors.Join(errs...)
 
@@ -47,23 +104,77 @@ func (r *VolumeGroupReconciler) sync(
 	// If activated and the volume group is missing physical volumes, remove them.
 	// If set to Fail and the volume group is missing physical volumes, return an error.
 	if lvm2go.IsLVMErrVGMissingPVs(err) {
+		missing, lastWrite := "", ""
 		if missingVG, missingPV, lastWritePath, ok := lvm2go.LVMErrVGMissingPVsDetails(err); ok {
 			logger = logger.WithValues(
 				"missingVolumeGroup", missingVG,
 				"missingPhysicalVolume", missingPV,
 				"lastWritePath", lastWritePath,
 			)
+			missing = missingPV
+			lastWrite = lastWritePath
 		}
 
-		if vg.Spec.DeviceLossSynchronizationPolicy != v1alpha1.DeviceLossSynchronizationPolicyFail {
-			logger.Info("device loss detected, removing missing physical volumes")
+		var pvs []lvmcmd.PhysicalVolumeState
+		if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+			pvs, err = lvmcmd.PhysicalVolumeStates(ctx, string(lvmvg.Name))
+			return
+		}); err != nil {
+			return fmt.Errorf("could not get missing physical volumes: %w", err)
+		}
+		trackMissingPhysicalVolumes(&vg.Status, pvs, missing, lastWrite, metav1.Now())
+
+		if replacementInProgress(vg) {
+			logger.Info("device loss detected, not applying DeviceLossSynchronizationPolicy while physical volumes are replaced")
+			SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+			return err
+		}
+
+		if vg.Spec.DeviceLossSynchronizationPolicy == v1alpha1.DeviceLossSynchronizationPolicyWaitForReturn {
+			// vgreduce --removemissing removes all missing physical volumes, so it waits for the grace period
+			// of every missing physical volume to expire.
+			if remaining := deviceLossGraceRemaining(vg, time.Now()); remaining > 0 {
+				logger.Info("device loss detected, waiting for missing physical volumes to return", "remaining", remaining)
+				SetSyncedOnHostWaitingForDeviceReturn(&vg.Status.Conditions, vg.GetGeneration(),
+					len(vg.Status.MissingPhysicalVolumes), time.Now().Add(remaining))
+				return ErrWaitingForDeviceReturn
+			}
+			logger.Info("device loss grace period expired, falling back to removal of missing physical volumes")
+		}
+
+		if policy := deviceLossRemovalPolicy(vg); policy != v1alpha1.DeviceLossSynchronizationPolicyFail {
 			opts := []lvm2go.VGReduceOption{lvmvg.Name, lvm2go.RemoveMissing(true)}
-			if vg.Spec.DeviceLossSynchronizationPolicy == v1alpha1.DeviceLossSynchronizationPolicyForceRemoveMissing {
+			operation := fmt.Sprintf("vgreduce %s --removemissing", lvmvg.Name)
+			if policy == v1alpha1.DeviceLossSynchronizationPolicyForceRemoveMissing {
 				opts = append(opts, lvm2go.Force(true))
+				operation += " --force"
+			}
+
+			if report || plan {
+				logger.Info("device loss detected, not removing missing physical volumes due to drift policy or plan mode")
+				drifts = append(drifts, v1alpha1.VolumeGroupDrift{
+					Field:     "physicalVolumeSelector",
+					Actual:    fmt.Sprintf("missing %s", missing),
+					Operation: operation,
+				})
+				if report {
+					vg.Status.Drift = drifts
+					SetDriftedReported(&vg.Status.Conditions, vg.GetGeneration(), len(drifts))
+				}
+				if plan {
+					vg.Status.PlannedOperations = planDrift(drifts)
+				}
+				SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+				return err
 			}
-			if err := r.LVM.VGReduce(ctx, opts...); err != nil {
+
+			logger.Info("device loss detected, removing missing physical volumes")
+			if err := r.withTimeout(ctx, vg, OperationMutation, "vgreduce --removemissing", func(ctx context.Context) error {
+				return r.LVM.VGReduce(ctx, opts...)
+			}); err != nil {
 				return fmt.Errorf("could not remove missing physical volumes (attempted due to DeviceLossSynchronizationPolicy): %w", err)
 			}
+			vg.Status.MissingPhysicalVolumes = nil
 			return r.sync(ctx, vg, lvmvg)
 		}
 
@@ -72,8 +183,40 @@ func (r *VolumeGroupReconciler) sync(
 		return err
 	}
 
+	if plan {
+		vg.Status.PlannedOperations = planDrift(drifts)
+	} else {
+		vg.Status.PlannedOperations = nil
+	}
+
+	switch {
+	case report:
+		vg.Status.Drift = drifts
+		if len(drifts) > 0 {
+			logger.Info("drift detected, not correcting due to drift policy", "count", len(drifts))
+			SetDriftedReported(&vg.Status.Conditions, vg.GetGeneration(), len(drifts))
+		} else {
+			SetDriftedNone(&vg.Status.Conditions, vg.GetGeneration())
+		}
+	case err == nil && !moving && !plan:
+		vg.Status.Drift = nil
+		if len(drifts) > 0 {
+			SetDriftedCorrected(&vg.Status.Conditions, vg.GetGeneration())
+		} else {
+			SetDriftedNone(&vg.Status.Conditions, vg.GetGeneration())
+		}
+	}
+
 	if err != nil {
 		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+	} else if plan {
+		logger.Info("plan mode, not running planned operations", "count", len(vg.Status.PlannedOperations))
+		SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(vg.Status.PlannedOperations))
+	} else if vg.Spec.Exported && !report {
+		SetSyncedOnHostExported(&vg.Status.Conditions, vg.GetGeneration())
+	} else if moving {
+		SetSyncedOnHostMoveInProgress(&vg.Status.Conditions, vg.GetGeneration())
+		return ErrPhysicalVolumeMoveInProgress
 	} else {
 		SetSyncedOnHostCreationOK(&vg.Status.Conditions, vg.GetGeneration())
 	}
@@ -81,140 +224,279 @@ func (r *VolumeGroupReconciler) sync(
 	return err
 }
 
-// syncTags calculates the difference between the desired tags and the actual tags and applies the difference to the volume group.
-func (r *VolumeGroupReconciler) syncTags(
-	ctx context.Context,
+// diffTags calculates the difference between the desired tags and the actual tags of the volume group.
+func (r *VolumeGroupReconciler) diffTags(
+	_ context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
+) ([]drift, error) {
 	name := getNameOnNode(vg)
-
-	return utils.SequentialTwoWaySync(
-		vg.Spec.Tags,
-		lvm.Tags,
-		func(tags []string) error {
-			return r.LVM.VGChange(ctx, name, lvm2go.Tags(tags))
-		},
-		func(tags []string) error {
-			return r.LVM.VGChange(ctx, name, lvm2go.DelTags(tags))
-		},
-	)
+	desired, actual := strings.Join(vg.Spec.Tags, ","), strings.Join(lvm.Tags, ",")
+
+	var drifts []drift
+	if add := utils.InLeftButNotInRight(vg.Spec.Tags, lvm.Tags); len(add) > 0 {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "tags",
+				Desired:   desired,
+				Actual:    actual,
+				Operation: fmt.Sprintf("vgchange %s --addtag %s", name, strings.Join(add, " --addtag ")),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "vgchange --addtag", func(ctx context.Context) error {
+					return r.LVM.VGChange(ctx, name, lvm2go.Tags(add))
+				})
+			},
+		})
+	}
+	if del := utils.InLeftButNotInRight(lvm.Tags, vg.Spec.Tags); len(del) > 0 {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "tags",
+				Desired:   desired,
+				Actual:    actual,
+				Operation: fmt.Sprintf("vgchange %s --deltag %s", name, strings.Join(del, " --deltag ")),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "vgchange --deltag", func(ctx context.Context) error {
+					return r.LVM.VGChange(ctx, name, lvm2go.DelTags(del))
+				})
+			},
+		})
+	}
+	return drifts, nil
 }
 
-// syncPVs calculates the difference between the desired PVs and the actual PVs and applies the difference to the volume group.
-func (r *VolumeGroupReconciler) syncPVs(
+// diffPVs calculates the difference between the desired PVs and the actual PVs of the volume group.
+func (r *VolumeGroupReconciler) diffPVs(
 	ctx context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
+) ([]drift, error) {
 	name := getNameOnNode(vg)
 
-	desiredState, err := getPhysicalVolumeNames(ctx, vg)
+	var desiredState []lvm2go.PhysicalVolumeName
+	var pvs []*lvm2go.PhysicalVolume
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lsblk", func(ctx context.Context) (err error) {
+		desiredState, err = getPhysicalVolumeNames(ctx, vg)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get physical volume names to sync spec: %w", err)
+	}
+	desiredState, prepare, err := r.prepareEncryptedDevices(ctx, vg, desiredState)
 	if err != nil {
-		return fmt.Errorf("could not get physical volume names to sync spec: %w", err)
+		return nil, fmt.Errorf("could not prepare physical volumes to sync spec: %w", err)
 	}
 
-	pvs, err := r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
-	if err != nil {
-		return fmt.Errorf("could not get pvs for calculation of state diff: %w", err)
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get pvs for calculation of state diff: %w", err)
 	}
 	currentState := utils.Map(pvs, func(pv *lvm2go.PhysicalVolume) lvm2go.PhysicalVolumeName {
 		return pv.Name
 	})
 
-	return utils.SequentialTwoWaySync(
-		desiredState,
-		currentState,
-		func(names []lvm2go.PhysicalVolumeName) error {
-			return r.LVM.VGExtend(ctx, name, lvm2go.PhysicalVolumeNames(names))
-		},
-		func(names []lvm2go.PhysicalVolumeName) error {
-			args := []lvm2go.VGReduceOption{name, lvm2go.PhysicalVolumeNames(names)}
-			switch vg.Spec.DeviceRemovalVolumePolicy {
-			case v1alpha1.DeviceRemovalVolumePolicyMoveAndReduce:
-				for _, pv := range names {
-					if err := r.LVM.PVMove(ctx, pv, lvm2go.PhysicalVolumeNames(desiredState)); err != nil {
+	prunePhysicalVolumeMoveStatus(&vg.Status, utils.InLeftButNotInRight(currentState, desiredState))
+
+	joinNames := func(names []lvm2go.PhysicalVolumeName) string {
+		return strings.Join(utils.Map(names, func(name lvm2go.PhysicalVolumeName) string {
+			return string(name)
+		}), " ")
+	}
+	desired, actual := joinNames(desiredState), joinNames(currentState)
+
+	drifts := prepare
+	if add := utils.InLeftButNotInRight(desiredState, currentState); len(add) > 0 {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "physicalVolumeSelector",
+				Desired:   desired,
+				Actual:    actual,
+				Operation: fmt.Sprintf("vgextend %s %s", name, joinNames(add)),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationLong, "vgextend", func(ctx context.Context) error {
+					return r.LVM.VGExtend(ctx, name, lvm2go.PhysicalVolumeNames(add))
+				})
+			},
+		})
+	}
+	if remove := utils.InLeftButNotInRight(currentState, desiredState); len(remove) > 0 {
+		args := []lvm2go.VGReduceOption{name, lvm2go.PhysicalVolumeNames(remove)}
+		operation := fmt.Sprintf("vgreduce %s %s", name, joinNames(remove))
+		switch vg.Spec.DeviceRemovalVolumePolicy {
+		case v1alpha1.DeviceRemovalVolumePolicyMoveAndReduce:
+			operation = fmt.Sprintf("pvmove %s, %s", joinNames(remove), operation)
+		case v1alpha1.DeviceRemovalVolumePolicyForceReduce:
+			args = append(args, lvm2go.Force(true))
+			operation += " --force"
+		}
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "physicalVolumeSelector",
+				Desired:   desired,
+				Actual:    actual,
+				Operation: operation,
+			},
+			correct: func(ctx context.Context) error {
+				if vg.Spec.DeviceRemovalVolumePolicy == v1alpha1.DeviceRemovalVolumePolicyMoveAndReduce {
+					// Moving extents can take hours, so the pvmoves are run in the background and
+					// the volume group is only reduced once all of them have completed.
+					if err := r.withTimeout(ctx, vg, OperationMutation, "pvmove", func(ctx context.Context) error {
+						return r.movePhysicalVolumes(ctx, vg, lvm, remove, desiredState)
+					}); err != nil {
 						return err
 					}
 				}
-			case v1alpha1.DeviceRemovalVolumePolicyForceReduce:
-				args = append(args, lvm2go.Force(true))
-			}
-			return r.LVM.VGReduce(ctx, args...)
-		},
-	)
+				return r.withTimeout(ctx, vg, OperationMutation, "vgreduce", func(ctx context.Context) error {
+					return r.LVM.VGReduce(ctx, args...)
+				})
+			},
+		})
+	}
+	return drifts, nil
 }
 
-// syncName calculates the difference between the desired name and the actual name and renames the volume group if necessary.
-func (r *VolumeGroupReconciler) syncName(
-	ctx context.Context,
+// diffName calculates the difference between the desired name and the actual name of the volume group.
+func (r *VolumeGroupReconciler) diffName(
+	_ context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
+) ([]drift, error) {
 	desired := getNameOnNode(vg)
 	if lvm.Name == desired {
-		return nil
+		return nil, nil
 	}
-	return r.LVM.VGRename(ctx, lvm.Name, desired)
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "nameOnNode",
+			Desired:   string(desired),
+			Actual:    string(lvm.Name),
+			Operation: fmt.Sprintf("vgrename %s %s", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgrename", func(ctx context.Context) error {
+				return r.LVM.VGRename(ctx, lvm.Name, desired)
+			})
+		},
+	}}, nil
 }
 
-// syncMaximumVolumes synchronizes the maximum number of physical and logical volumes in the volume group.
-func (r *VolumeGroupReconciler) syncMaximumVolumes(
-	ctx context.Context,
+// diffMaximumVolumes calculates the difference in the maximum number of physical and logical volumes in the volume group.
+func (r *VolumeGroupReconciler) diffMaximumVolumes(
+	_ context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
-	if vg.Spec.MaximumPhysicalVolumes != nil && lvm.MaxPv != *vg.Spec.MaximumPhysicalVolumes {
-		if err := r.LVM.VGChange(ctx, lvm.Name, lvm2go.MaximumPhysicalVolumes(*vg.Spec.MaximumPhysicalVolumes)); err != nil {
-			return fmt.Errorf("could not set maximum physical volumes: %w", err)
-		}
+) ([]drift, error) {
+	var drifts []drift
+	if desired := vg.Spec.MaximumPhysicalVolumes; desired != nil && lvm.MaxPv != *desired {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "maximumPhysicalVolumes",
+				Desired:   strconv.FormatInt(*desired, 10),
+				Actual:    strconv.FormatInt(lvm.MaxPv, 10),
+				Operation: fmt.Sprintf("vgchange %s --maxphysicalvolumes %d", lvm.Name, *desired),
+			},
+			correct: func(ctx context.Context) error {
+				if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange --maxphysicalvolumes", func(ctx context.Context) error {
+					return r.LVM.VGChange(ctx, lvm.Name, lvm2go.MaximumPhysicalVolumes(*desired))
+				}); err != nil {
+					return fmt.Errorf("could not set maximum physical volumes: %w", err)
+				}
+				return nil
+			},
+		})
 	}
-	if vg.Spec.MaximumLogicalVolumes != nil && lvm.MaxLv != *vg.Spec.MaximumLogicalVolumes {
-		if err := r.LVM.VGChange(ctx, lvm.Name, lvm2go.MaximumLogicalVolumes(*vg.Spec.MaximumLogicalVolumes)); err != nil {
-			return fmt.Errorf("could not set maximum logical volumes: %w", err)
-		}
+	if desired := vg.Spec.MaximumLogicalVolumes; desired != nil && lvm.MaxLv != *desired {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     "maximumLogicalVolumes",
+				Desired:   strconv.FormatInt(*desired, 10),
+				Actual:    strconv.FormatInt(lvm.MaxLv, 10),
+				Operation: fmt.Sprintf("vgchange %s --maxlogicalvolumes %d", lvm.Name, *desired),
+			},
+			correct: func(ctx context.Context) error {
+				if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange --maxlogicalvolumes", func(ctx context.Context) error {
+					return r.LVM.VGChange(ctx, lvm.Name, lvm2go.MaximumLogicalVolumes(*desired))
+				}); err != nil {
+					return fmt.Errorf("could not set maximum logical volumes: %w", err)
+				}
+				return nil
+			},
+		})
 	}
-
-	return nil
+	return drifts, nil
 }
 
-// syncAllocationPolicy synchronizes the allocation policy of the volume group.
-func (r *VolumeGroupReconciler) syncAllocationPolicy(
-	ctx context.Context,
+// diffAllocationPolicy calculates the difference in the allocation policy of the volume group.
+func (r *VolumeGroupReconciler) diffAllocationPolicy(
+	_ context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
+) ([]drift, error) {
 	if vg.Spec.AllocationPolicy == nil {
-		return nil
+		return nil, nil
 	}
 
 	desired := lvm2go.AllocationPolicy(utils.ToSnakeCase(string(*vg.Spec.AllocationPolicy)))
 
 	if lvm.AllocationPolicy == desired {
-		return nil
+		return nil, nil
 	}
 
-	return r.LVM.VGChange(ctx, lvm.Name, desired)
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "allocationPolicy",
+			Desired:   string(desired),
+			Actual:    string(lvm.AllocationPolicy),
+			Operation: fmt.Sprintf("vgchange %s --alloc %s", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --alloc", func(ctx context.Context) error {
+				return r.LVM.VGChange(ctx, lvm.Name, desired)
+			})
+		},
+	}}, nil
 }
 
-func (r *VolumeGroupReconciler) syncAutoActivation(
-	ctx context.Context,
+// diffAutoActivation calculates the difference in the autoactivation property of the volume group.
+func (r *VolumeGroupReconciler) diffAutoActivation(
+	_ context.Context,
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
-) error {
+) ([]drift, error) {
 	if vg.Spec.AutoActivation == nil {
-		return nil
+		return nil, nil
 	}
 
 	desired := convertToAutoActivation(vg.Spec.AutoActivation)
 
 	if lvm.AutoActivation.True() && desired == lvm2go.SetAutoActivate {
-		return nil
+		return nil, nil
 	} else if !lvm.AutoActivation.True() && desired == lvm2go.SetNoAutoActivate {
-		return nil
+		return nil, nil
+	}
+
+	actual := lvm2go.SetNoAutoActivate
+	if lvm.AutoActivation.True() {
+		actual = lvm2go.SetAutoActivate
 	}
 
-	return r.LVM.VGChange(ctx, lvm.Name, desired)
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "autoActivation",
+			Desired:   string(desired),
+			Actual:    string(actual),
+			Operation: fmt.Sprintf("vgchange %s --setautoactivation %s", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --setautoactivation", func(ctx context.Context) error {
+				return r.LVM.VGChange(ctx, lvm.Name, desired)
+			})
+		},
+	}}, nil
 }
 
 // syncStatus synchronizes the status of the volume group with the actual state from lvm2.
@@ -223,11 +505,31 @@ func (r *VolumeGroupReconciler) syncStatus(
 	vg *v1alpha1.VolumeGroup,
 	lvm *lvm2go.VolumeGroup,
 ) (err error) {
-	pvs, err := r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
-	if err != nil {
+	var pvs []*lvm2go.PhysicalVolume
+	if err = r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+		pvs, err = r.LVM.PVs(ctx, lvm.Name, lvm2go.UnitBytes)
+		return
+	}); err != nil {
 		return fmt.Errorf("could not get pvs for status summary: %w", err)
 	}
 
+	if lvm.MissingPVCount == 0 {
+		vg.Status.MissingPhysicalVolumes = nil
+	} else if len(vg.Status.MissingPhysicalVolumes) > 0 {
+		var states []lvmcmd.PhysicalVolumeState
+		if err = r.withTimeout(ctx, vg, OperationDiscovery, "pvs", func(ctx context.Context) (err error) {
+			states, err = lvmcmd.PhysicalVolumeStates(ctx, string(lvm.Name))
+			return
+		}); err != nil {
+			return fmt.Errorf("could not get missing physical volumes for status summary: %w", err)
+		}
+		trackMissingPhysicalVolumes(&vg.Status, states, "", "", metav1.Now())
+	}
+
+	if vg.Status.Encryption, err = r.encryptionStatus(ctx, vg, pvs); err != nil {
+		return fmt.Errorf("could not get encrypted devices for status summary: %w", err)
+	}
+
 	vg.Status.PhysicalVolumes = make([]v1alpha1.PhysicalVolumeStatus, len(pvs))
 	for i, pv := range pvs {
 		vg.Status.PhysicalVolumes[i].Name = string(pv.Name)
@@ -261,6 +563,7 @@ func (r *VolumeGroupReconciler) syncStatus(
 		vg.Status.PhysicalVolumes[i].Attributes = pv.Attr.String()
 		vg.Status.PhysicalVolumes[i].Minor = pv.Minor
 		vg.Status.PhysicalVolumes[i].Major = pv.Major
+		vg.Status.PhysicalVolumes[i].Health = r.physicalVolumeHealth(ctx, vg, pv)
 	}
 
 	if vg.Status.ExtentSize, err = convertSizeToQuantity(lvm.ExtentSize); err != nil {
@@ -272,6 +575,38 @@ func (r *VolumeGroupReconciler) syncStatus(
 	if vg.Status.Free, err = convertSizeToQuantity(lvm.Free); err != nil {
 		return err
 	}
+	syncAllocatable(vg)
+	// Logical volumes and locks of exported volume groups cannot be listed.
+	if vg.Status.Exported = isExported(lvm); vg.Status.Exported {
+		vg.Status.Activation = v1alpha1.ActivationInactive
+	} else {
+		if vg.Status.Activation, err = r.activation(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get activation for status summary: %w", err)
+		}
+		if vg.Status.MetadataCopies, err = r.metadataCopies(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get metadata copies for status summary: %w", err)
+		}
+		if vg.Status.MetadataProfile, err = r.metadataProfile(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get metadata profile for status summary: %w", err)
+		}
+		if vg.Status.ThinPools, err = r.thinPoolStatus(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get thin pools for status summary: %w", err)
+		}
+		if vg.Status.Cache, err = r.cacheStatus(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get caches for status summary: %w", err)
+		}
+		if vg.Status.LockType, err = r.lockType(ctx, vg, lvm); err != nil {
+			return fmt.Errorf("could not get lock type for status summary: %w", err)
+		}
+		if vg.Status.LockType != lockTypeNone {
+			started, err := r.lockStarted(ctx, vg, string(lvm.Name))
+			if err != nil {
+				return fmt.Errorf("could not get lockspace for status summary: %w", err)
+			}
+			setLockStatus(&vg.Status, r.NodeName, started, nil)
+		}
+	}
+
 	vg.Status.Name = string(lvm.Name)
 	vg.Status.UUID = lvm.UUID
 	vg.Status.SysID = lvm.SysID
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// HostEventSource reports changes of volume groups on the node by their name on the node,
+// including changes made outside of the controller.
+type HostEventSource interface {
+	// Events returns the channel the names of changed volume groups are emitted on.
+	Events() <-chan event.TypedGenericEvent[string]
+	// Covers returns true if changes of the volume group are currently reported by the source.
+	Covers(name string) bool
+}
+
+// syncInterval returns the jittered interval after which the volume group is synced again, or 0 if it should not
+// be synced periodically. The interval of the VolumeGroup spec takes precedence over the interval of the controller,
+// which is ignored while the volume group is covered by the host event source.
+func (r *VolumeGroupReconciler) syncInterval(vg *v1alpha1.VolumeGroup) time.Duration {
+	interval := r.SyncInterval
+	if vg.Spec.SyncInterval != nil {
+		interval = vg.Spec.SyncInterval.Duration
+	} else if r.HostEvents != nil && r.HostEvents.Covers(string(getNameOnNode(vg))) {
+		return 0
+	}
+
+	if interval <= 0 {
+		return 0
+	}
+	// wait.Jitter falls back to a factor of 1 for factors of 0 or less, so disabled jitter is handled here.
+	if r.SyncJitter <= 0 {
+		return interval
+	}
+	return wait.Jitter(interval, r.SyncJitter)
+}
+
+// volumeGroupsForHostEvent maps the name of a volume group changed on the node to the VolumeGroups managing it.
+func (r *VolumeGroupReconciler) volumeGroupsForHostEvent(ctx context.Context, name string) []reconcile.Request {
+	vgs := &v1alpha1.VolumeGroupList{}
+	if err := r.List(ctx, vgs); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list volume groups for host event", "lvm_name", name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range vgs.Items {
+		vg := &vgs.Items[i]
+		if vg.Spec.NodeName != r.NodeName || string(getNameOnNode(vg)) != name {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(vg)})
+	}
+	return requests
+}
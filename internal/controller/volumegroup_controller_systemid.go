@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"github.com/topolvm/topovgm/internal/lvmerr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// diffSystemID calculates the difference between the desired and the actual system ID of the volume group.
+func (r *VolumeGroupReconciler) diffSystemID(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if vg.Spec.SystemIDPolicy == nil {
+		return nil, nil
+	}
+
+	desired, err := r.systemID(ctx, vg)
+	if err != nil {
+		return nil, err
+	}
+	if lvm.SysID == desired {
+		return nil, nil
+	}
+
+	return []drift{{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     "systemIDPolicy",
+			Desired:   desired,
+			Actual:    lvm.SysID,
+			Operation: fmt.Sprintf("vgchange %s --systemid %s", lvm.Name, desired),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationMutation, "vgchange --systemid", func(ctx context.Context) error {
+				return lvmcmd.ChangeSystemID(ctx, string(lvm.Name), desired)
+			})
+		},
+	}}, nil
+}
+
+// takeoverVG changes the system ID of a volume group that is foreign to the node because it is owned by
+// SystemIDPolicy.TakeoverFrom to the system ID of the node.
+// It returns false if there is no volume group to take over.
+func (r *VolumeGroupReconciler) takeoverVG(ctx context.Context, vg *v1alpha1.VolumeGroup) (bool, error) {
+	policy := vg.Spec.SystemIDPolicy
+	name := string(getNameOnNode(vg))
+
+	var owner string
+	var found bool
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "vgs --foreign", func(ctx context.Context) (err error) {
+		owner, found, err = lvmcmd.ForeignSystemID(ctx, name)
+		return
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return false, err
+	}
+	if !found {
+		return false, nil
+	}
+	if owner != policy.TakeoverFrom {
+		err := lvmerr.NewTerminal(fmt.Errorf(
+			"volume group %s is owned by system ID %q instead of %q and cannot be taken over", name, owner, policy.TakeoverFrom))
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return false, err
+	}
+
+	desired, err := r.systemID(ctx, vg)
+	if err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return false, err
+	}
+
+	if isPlanOnly(vg) {
+		vg.Status.PlannedOperations = []v1alpha1.PlannedOperation{{
+			Field: "systemIDPolicy",
+			Operation: fmt.Sprintf(`vgchange %s --systemid %s --config local/extra_system_ids=["%s"]`,
+				name, desired, policy.TakeoverFrom),
+		}}
+		SetSyncedOnHostPlanned(&vg.Status.Conditions, vg.GetGeneration(), len(vg.Status.PlannedOperations))
+		return true, nil
+	}
+
+	log.FromContext(ctx).Info("taking over volume group from other host", "from", policy.TakeoverFrom, "to", desired)
+	if err := r.withTimeout(ctx, vg, OperationMutation, "vgchange --systemid", func(ctx context.Context) error {
+		return lvmcmd.ChangeSystemID(ctx, name, desired, policy.TakeoverFrom)
+	}); err != nil {
+		SetSyncedOnHostCreationFailed(&vg.Status.Conditions, vg.GetGeneration(), err)
+		return false, err
+	}
+	return true, nil
+}
+
+// systemID returns the desired system ID of the volume group from its SystemIDPolicy.
+// It fails terminally if the system ID does not match the one of the node, as the volume group
+// would become foreign to the node and could not be managed anymore.
+func (r *VolumeGroupReconciler) systemID(ctx context.Context, vg *v1alpha1.VolumeGroup) (string, error) {
+	var host string
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvm systemid", func(ctx context.Context) (err error) {
+		host, err = lvmcmd.HostSystemID(ctx)
+		return
+	}); err != nil {
+		return "", fmt.Errorf("could not get system ID of node: %w", err)
+	}
+
+	var desired string
+	switch vg.Spec.SystemIDPolicy.Source {
+	case v1alpha1.SystemIDSourceNodeName:
+		desired = r.NodeName
+	case v1alpha1.SystemIDSourceValue:
+		desired = vg.Spec.SystemIDPolicy.Value
+	default:
+		desired = host
+	}
+
+	if desired == "" {
+		return "", lvmerr.NewTerminal(errors.New(
+			"the node has no system ID, configure system_id_source in lvm.conf of the node, see lvmsystemid(7)"))
+	}
+	if desired != host {
+		return "", lvmerr.NewTerminal(fmt.Errorf(
+			"desired system ID %q does not match system ID %q of the node, configure the system ID in lvm.conf of the node, "+
+				"e.g. with system_id_source = \"lvmlocal\" and system_id = %q in lvmlocal.conf", desired, host, desired))
+	}
+	return desired, nil
+}
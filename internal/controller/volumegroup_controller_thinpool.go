@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// diffThinPools calculates the difference between the thin pools in the spec and in the volume group.
+// Thin pools are created and grown, while requests to shrink them are refused with a condition.
+func (r *VolumeGroupReconciler) diffThinPools(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]drift, error) {
+	if len(vg.Spec.ThinPools) == 0 {
+		meta.RemoveStatusCondition(&vg.Status.Conditions, ConditionTypeThinPoolShrinkRefused)
+		return nil, nil
+	}
+
+	pools, err := r.thinPools(ctx, vg, lvm)
+	if err != nil {
+		return nil, fmt.Errorf("could not get thin pools to sync spec: %w", err)
+	}
+	existing := make(map[string]lvmcmd.ThinPool, len(pools))
+	for _, pool := range pools {
+		existing[pool.Name] = pool
+	}
+
+	var profileDir string
+	var drifts []drift
+	var refused []string
+	for _, pool := range vg.Spec.ThinPools {
+		actual, exists := existing[pool.Name]
+
+		extents := thinPoolExtents(pool, lvm)
+		metadataSize := int64(0)
+		if pool.MetadataSize != nil {
+			metadataSize = roundUpToExtent(pool.MetadataSize.Value(), lvm)
+		}
+
+		switch {
+		case !exists:
+			drifts = append(drifts, r.createThinPoolDrift(vg, lvm, pool, extents, metadataSize))
+		case thinPoolShrinkRequested(pool, actual, extents, metadataSize, extentSize(lvm)):
+			refused = append(refused, pool.Name)
+		default:
+			if actualExtents := actual.Size / extentSize(lvm); extents > actualExtents {
+				drifts = append(drifts, r.extendThinPoolDrift(vg, lvm, pool.Name, extents, actualExtents))
+			}
+			if metadataSize > actual.MetadataSize {
+				drifts = append(drifts, r.extendThinPoolMetadataDrift(vg, lvm, pool.Name, metadataSize, actual.MetadataSize))
+			}
+		}
+
+		if pool.Autoextend == nil {
+			continue
+		}
+		if profileDir == "" {
+			if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvmconfig", func(ctx context.Context) (err error) {
+				profileDir, err = lvmcmd.ProfileDir(ctx)
+				return
+			}); err != nil {
+				return nil, fmt.Errorf("could not get profile directory to sync thin pool autoextend: %w", err)
+			}
+		}
+		autoextendDrifts, err := r.diffThinPoolAutoextend(ctx, vg, lvm, pool, actual, profileDir)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, autoextendDrifts...)
+	}
+
+	if len(refused) > 0 {
+		SetThinPoolShrinkRefused(&vg.Status.Conditions, vg.GetGeneration(), refused)
+	} else {
+		SetThinPoolShrinkRefusedNone(&vg.Status.Conditions, vg.GetGeneration())
+	}
+
+	return drifts, nil
+}
+
+func (r *VolumeGroupReconciler) createThinPoolDrift(
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	pool v1alpha1.ThinPool,
+	extents, metadataSize int64,
+) drift {
+	var chunkSize int64
+	if pool.ChunkSize != nil {
+		chunkSize = pool.ChunkSize.Value()
+	}
+	operation := fmt.Sprintf("lvcreate --type thin-pool --name %s --extents %d", pool.Name, extents)
+	if chunkSize > 0 {
+		operation += fmt.Sprintf(" --chunksize %db", chunkSize)
+	}
+	if metadataSize > 0 {
+		operation += fmt.Sprintf(" --poolmetadatasize %db", metadataSize)
+	}
+	return drift{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     fmt.Sprintf("thinPools[%s]", pool.Name),
+			Desired:   "present",
+			Actual:    "absent",
+			Operation: fmt.Sprintf("%s %s", operation, lvm.Name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationLong, "lvcreate --type thin-pool", func(ctx context.Context) error {
+				return lvmcmd.CreateThinPool(ctx, string(lvm.Name), pool.Name, extents, chunkSize, metadataSize)
+			})
+		},
+	}
+}
+
+func (r *VolumeGroupReconciler) extendThinPoolDrift(
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	name string,
+	extents, actualExtents int64,
+) drift {
+	return drift{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     fmt.Sprintf("thinPools[%s].size", name),
+			Desired:   fmt.Sprintf("%d extents", extents),
+			Actual:    fmt.Sprintf("%d extents", actualExtents),
+			Operation: fmt.Sprintf("lvextend --extents %d %s/%s", extents, lvm.Name, name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationLong, "lvextend", func(ctx context.Context) error {
+				return lvmcmd.ExtendThinPool(ctx, string(lvm.Name), name, extents)
+			})
+		},
+	}
+}
+
+func (r *VolumeGroupReconciler) extendThinPoolMetadataDrift(
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	name string,
+	metadataSize, actualMetadataSize int64,
+) drift {
+	return drift{
+		VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+			Field:     fmt.Sprintf("thinPools[%s].metadataSize", name),
+			Desired:   strconv.FormatInt(metadataSize, 10),
+			Actual:    strconv.FormatInt(actualMetadataSize, 10),
+			Operation: fmt.Sprintf("lvextend --poolmetadatasize %db %s/%s", metadataSize, lvm.Name, name),
+		},
+		correct: func(ctx context.Context) error {
+			return r.withTimeout(ctx, vg, OperationLong, "lvextend --poolmetadatasize", func(ctx context.Context) error {
+				return lvmcmd.ExtendThinPoolMetadata(ctx, string(lvm.Name), name, metadataSize)
+			})
+		},
+	}
+}
+
+// diffThinPoolAutoextend calculates the difference between the desired autoextend settings of the thin pool
+// and the metadata profile installed on the node and attached to the thin pool.
+func (r *VolumeGroupReconciler) diffThinPoolAutoextend(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+	pool v1alpha1.ThinPool,
+	actual lvmcmd.ThinPool,
+	profileDir string,
+) ([]drift, error) {
+	profile := thinPoolProfileName(lvm, pool.Name)
+	desired := thinPoolAutoextendProfile(pool.Autoextend)
+
+	var installed []byte
+	if err := r.withTimeout(ctx, vg, OperationDiscovery, "lvmconfig", func(ctx context.Context) (err error) {
+		installed, err = lvmcmd.InstalledProfile(ctx, profileDir, profile)
+		return
+	}); err != nil {
+		return nil, fmt.Errorf("could not get installed autoextend profile of thin pool %s: %w", pool.Name, err)
+	}
+
+	var drifts []drift
+	if !bytes.Equal(desired, installed) {
+		state := "outdated"
+		if len(installed) == 0 {
+			state = "missing"
+		}
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field: fmt.Sprintf("thinPools[%s].autoextend", pool.Name),
+				Desired: fmt.Sprintf("threshold=%d,percent=%d",
+					pool.Autoextend.Threshold, pool.Autoextend.Percent),
+				Actual:    state,
+				Operation: fmt.Sprintf("install %s/%s.profile", profileDir, profile),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "install profile", func(ctx context.Context) error {
+					return lvmcmd.InstallProfile(ctx, profileDir, profile, desired)
+				})
+			},
+		})
+	}
+
+	if actual.Profile != profile {
+		drifts = append(drifts, drift{
+			VolumeGroupDrift: v1alpha1.VolumeGroupDrift{
+				Field:     fmt.Sprintf("thinPools[%s].autoextend", pool.Name),
+				Desired:   profile,
+				Actual:    actual.Profile,
+				Operation: fmt.Sprintf("lvchange --metadataprofile %s %s/%s", profile, lvm.Name, pool.Name),
+			},
+			correct: func(ctx context.Context) error {
+				return r.withTimeout(ctx, vg, OperationMutation, "lvchange --metadataprofile", func(ctx context.Context) error {
+					return lvmcmd.ChangeLogicalVolumeProfile(ctx, string(lvm.Name), pool.Name, profile)
+				})
+			},
+		})
+	}
+
+	return drifts, nil
+}
+
+// thinPoolStatus returns the status of the thin pools in the volume group declared in the spec.
+func (r *VolumeGroupReconciler) thinPoolStatus(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) ([]v1alpha1.ThinPoolStatus, error) {
+	if len(vg.Spec.ThinPools) == 0 {
+		return nil, nil
+	}
+	pools, err := r.thinPools(ctx, vg, lvm)
+	if err != nil {
+		return nil, err
+	}
+	var status []v1alpha1.ThinPoolStatus
+	for _, pool := range pools {
+		if !slices.ContainsFunc(vg.Spec.ThinPools, func(p v1alpha1.ThinPool) bool { return p.Name == pool.Name }) {
+			continue
+		}
+		status = append(status, v1alpha1.ThinPoolStatus{
+			Name:            pool.Name,
+			Size:            resource.NewQuantity(pool.Size, resource.BinarySI),
+			MetadataSize:    resource.NewQuantity(pool.MetadataSize, resource.BinarySI),
+			DataPercent:     pool.DataPercent,
+			MetadataPercent: pool.MetadataPercent,
+		})
+	}
+	return status, nil
+}
+
+// thinPools returns the thin pools in the volume group.
+func (r *VolumeGroupReconciler) thinPools(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	lvm *lvm2go.VolumeGroup,
+) (pools []lvmcmd.ThinPool, err error) {
+	err = r.withTimeout(ctx, vg, OperationDiscovery, "lvs", func(ctx context.Context) (err error) {
+		pools, err = lvmcmd.ThinPools(ctx, string(lvm.Name))
+		return
+	})
+	return
+}
+
+// thinPoolExtents returns the number of extents of the data of the thin pool,
+// rounding an absolute size up and a percentage of the volume group down.
+func thinPoolExtents(pool v1alpha1.ThinPool, lvm *lvm2go.VolumeGroup) int64 {
+	if pool.Percent != nil {
+		return lvm.ExtentCount * int64(*pool.Percent) / 100
+	}
+	return roundUpToExtent(pool.Size.Value(), lvm) / extentSize(lvm)
+}
+
+// thinPoolShrinkRequested returns true if the spec requests a smaller thin pool or metadata than in the volume group.
+// With autoextend, lvm2 grows the data and metadata of the thin pool beyond the spec, which are minimums then.
+func thinPoolShrinkRequested(pool v1alpha1.ThinPool, actual lvmcmd.ThinPool, extents, metadataSize, extentSize int64) bool {
+	if pool.Autoextend != nil {
+		return false
+	}
+	return extents < actual.Size/extentSize || metadataSize > 0 && metadataSize < actual.MetadataSize
+}
+
+// roundUpToExtent rounds the size in bytes up to a multiple of the extent size of the volume group.
+func roundUpToExtent(size int64, lvm *lvm2go.VolumeGroup) int64 {
+	extent := extentSize(lvm)
+	return (size + extent - 1) / extent * extent
+}
+
+// extentSize returns the extent size of the volume group in bytes, which is discovered with lvm2go.UnitBytes.
+func extentSize(lvm *lvm2go.VolumeGroup) int64 {
+	return int64(lvm.ExtentSize.Val)
+}
+
+// thinPoolProfileName returns the name of the metadata profile carrying the autoextend settings of the thin pool.
+func thinPoolProfileName(lvm *lvm2go.VolumeGroup, pool string) string {
+	return fmt.Sprintf("topovgm-%s-%s", lvm.Name, pool)
+}
+
+// thinPoolAutoextendProfile returns the content of the metadata profile with the autoextend settings.
+func thinPoolAutoextendProfile(autoextend *v1alpha1.ThinPoolAutoextend) []byte {
+	return []byte(fmt.Sprintf("activation {\n\tthin_pool_autoextend_threshold=%d\n\tthin_pool_autoextend_percent=%d\n}\n",
+		autoextend.Threshold, autoextend.Percent))
+}
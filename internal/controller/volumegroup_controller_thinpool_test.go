@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const testExtentSize = 4 << 20
+
+func testVolumeGroupWithExtents(count int64) *lvm2go.VolumeGroup {
+	return &lvm2go.VolumeGroup{ExtentSize: lvm2go.Size{Val: testExtentSize}, ExtentCount: count}
+}
+
+func TestRoundUpToExtent(t *testing.T) {
+	lvm := testVolumeGroupWithExtents(100)
+	for size, expected := range map[int64]int64{
+		0:                  0,
+		1:                  testExtentSize,
+		testExtentSize:     testExtentSize,
+		testExtentSize + 1: 2 * testExtentSize,
+	} {
+		if actual := roundUpToExtent(size, lvm); actual != expected {
+			t.Errorf("expected %d to be rounded up to %d, got %d", size, expected, actual)
+		}
+	}
+}
+
+func TestThinPoolExtents(t *testing.T) {
+	lvm := testVolumeGroupWithExtents(250)
+	size := resource.MustParse("10Mi")
+	if extents := thinPoolExtents(v1alpha1.ThinPool{Size: &size}, lvm); extents != 3 {
+		t.Errorf("expected 10Mi to take 3 extents of 4Mi, got %d", extents)
+	}
+	if extents := thinPoolExtents(v1alpha1.ThinPool{Percent: ptr[int32](50)}, lvm); extents != 125 {
+		t.Errorf("expected 50%% of 250 extents to be 125 extents, got %d", extents)
+	}
+}
+
+func TestThinPoolShrinkRequested(t *testing.T) {
+	actual := lvmcmd.ThinPool{Size: 10 * testExtentSize, MetadataSize: 8 << 20}
+	autoextend := &v1alpha1.ThinPoolAutoextend{Threshold: 80, Percent: 20}
+	for name, tc := range map[string]struct {
+		pool         v1alpha1.ThinPool
+		extents      int64
+		metadataSize int64
+		expected     bool
+	}{
+		"equal":                 {extents: 10, expected: false},
+		"grow":                  {extents: 12, metadataSize: 16 << 20, expected: false},
+		"shrink data":           {extents: 8, expected: true},
+		"shrink metadata":       {extents: 10, metadataSize: 4 << 20, expected: true},
+		"unspecified metadata":  {extents: 10, metadataSize: 0, expected: false},
+		"autoextended data":     {pool: v1alpha1.ThinPool{Autoextend: autoextend}, extents: 8, expected: false},
+		"autoextended metadata": {pool: v1alpha1.ThinPool{Autoextend: autoextend}, extents: 10, metadataSize: 4 << 20, expected: false},
+	} {
+		if requested := thinPoolShrinkRequested(tc.pool, actual, tc.extents, tc.metadataSize, testExtentSize); requested != tc.expected {
+			t.Errorf("%s: expected shrink requested to be %t, got %t", name, tc.expected, requested)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
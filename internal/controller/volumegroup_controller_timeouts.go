@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/topolvm/topovgm/api/v1alpha1"
+)
+
+// OperationKind is the kind of operation run against lvm2, used to determine its timeout.
+type OperationKind string
+
+const (
+	// OperationDiscovery reads the state of the volume group and its devices from the host, e.g. vgs, pvs or lsblk.
+	OperationDiscovery OperationKind = "Discovery"
+	// OperationMutation changes the volume group without touching the data on its devices, e.g. vgchange or vgreduce.
+	OperationMutation OperationKind = "Mutation"
+	// OperationLong initializes or wipes devices and scales with their number and size, e.g. vgcreate, vgextend or vgremove.
+	OperationLong OperationKind = "LongOperation"
+)
+
+// Timeouts are the default timeouts of operations run against lvm2 by kind.
+// A timeout of 0 or less disables the timeout for the kind.
+type Timeouts struct {
+	Discovery     time.Duration
+	Mutation      time.Duration
+	LongOperation time.Duration
+}
+
+// TimeoutError is returned when an operation run against lvm2 exceeded its timeout.
+type TimeoutError struct {
+	Operation string
+	Kind      OperationKind
+	Timeout   time.Duration
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s operation %q timed out after %s: %v", e.Kind, e.Operation, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports a TimeoutError as context.DeadlineExceeded, so that timeouts are classified as transient.
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// withTimeout runs the operation with the timeout for its kind, taking overrides from the VolumeGroup into account.
+// If the operation fails because its timeout was exceeded, a TimeoutError is returned.
+func (r *VolumeGroupReconciler) withTimeout(
+	ctx context.Context,
+	vg *v1alpha1.VolumeGroup,
+	kind OperationKind,
+	operation string,
+	op func(ctx context.Context) error,
+) error {
+	timeout := r.timeout(vg, kind)
+	if timeout <= 0 {
+		return op(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := op(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Operation: operation, Kind: kind, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// timeout returns the timeout for the kind of operation, preferring the override from the VolumeGroup spec.
+func (r *VolumeGroupReconciler) timeout(vg *v1alpha1.VolumeGroup, kind OperationKind) time.Duration {
+	overrides := vg.Spec.Timeouts
+	if overrides == nil {
+		overrides = &v1alpha1.OperationTimeouts{}
+	}
+
+	switch kind {
+	case OperationDiscovery:
+		if overrides.Discovery != nil {
+			return overrides.Discovery.Duration
+		}
+		return r.Timeouts.Discovery
+	case OperationMutation:
+		if overrides.Mutation != nil {
+			return overrides.Mutation.Duration
+		}
+		return r.Timeouts.Mutation
+	case OperationLong:
+		if overrides.LongOperation != nil {
+			return overrides.LongOperation.Duration
+		}
+		return r.Timeouts.LongOperation
+	}
+	return 0
+}
@@ -2,10 +2,12 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jakobmoellerdev/lvm2go"
 	"github.com/topolvm/topovgm/api/v1alpha1"
+	"github.com/topolvm/topovgm/internal/lvmerr"
 	"github.com/topolvm/topovgm/internal/selector"
 	"github.com/topolvm/topovgm/internal/utils"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -26,6 +28,10 @@ func getNameOnNode(vg *v1alpha1.VolumeGroup) lvm2go.VolumeGroupName {
 	return lvm2go.VolumeGroupName(*vg.Spec.NameOnNode)
 }
 
+// ErrNoDeviceMatched is returned if the PhysicalVolumeSelector matches no device. It is only terminal on creation,
+// as the devices of an existing volume group might not be enumerated yet, e.g. during the boot of the node.
+var ErrNoDeviceMatched = errors.New("physical volume selector did not match any device")
+
 // getPhysicalVolumeNames retrieves the physical volume names from the VolumeGroup spec based on the provided PhysicalVolumeSelector.
 // It uses selector.DevicesMatchingSelector to get the devices matching the selector and maps them to PhysicalVolumeName.
 //
@@ -35,7 +41,7 @@ func getNameOnNode(vg *v1alpha1.VolumeGroup) lvm2go.VolumeGroupName {
 //
 // Returns:
 // - A slice of PhysicalVolumeName containing the names of the physical volumes.
-// - An error if there was an issue retrieving the devices matching the selector.
+// - An error if there was an issue retrieving the devices matching the selector or no device matched it.
 func getPhysicalVolumeNames(ctx context.Context, vg *v1alpha1.VolumeGroup) ([]lvm2go.PhysicalVolumeName, error) {
 	fromSelector, err := selector.DevicesMatchingSelector(ctx, vg.Spec.PhysicalVolumeSelector)
 
@@ -43,6 +49,10 @@ func getPhysicalVolumeNames(ctx context.Context, vg *v1alpha1.VolumeGroup) ([]lv
 		return nil, fmt.Errorf("could not get devices matching selector: %w", err)
 	}
 
+	if len(fromSelector) == 0 {
+		return nil, ErrNoDeviceMatched
+	}
+
 	return utils.Map(fromSelector, func(pv string) lvm2go.PhysicalVolumeName {
 		return lvm2go.PhysicalVolumeName(pv)
 	}), nil
@@ -59,7 +69,10 @@ func convertToVGCreateOptions(ctx context.Context, vg *v1alpha1.VolumeGroup) (*l
 
 	var err error
 	opts.PhysicalVolumeNames, err = getPhysicalVolumeNames(ctx, vg)
-	if err != nil {
+	if errors.Is(err, ErrNoDeviceMatched) {
+		// Without devices the volume group cannot be created, so the spec has to change.
+		return nil, lvmerr.NewTerminal(fmt.Errorf("could not get physical volume names from spec: %w", err))
+	} else if err != nil {
 		return nil, fmt.Errorf("could not get physical volume names from spec: %w", err)
 	}
 
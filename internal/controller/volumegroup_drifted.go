@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeVolumeGroupDrifted is a condition type that indicates whether the volume group on the node differs from the spec.
+	ConditionTypeVolumeGroupDrifted = "Drifted"
+	ReasonNoDrift                   = "NoDrift"
+	ReasonDriftCorrected            = "DriftCorrected"
+	ReasonDriftReported             = "DriftReported"
+	MessageNoDrift                  = "The volume group on the node matches the spec."
+	MessageDriftCorrected           = "The volume group on the node differed from the spec and was corrected."
+)
+
+var Drifted = metav1.Condition{
+	Type:    ConditionTypeVolumeGroupDrifted,
+	Status:  metav1.ConditionFalse,
+	Reason:  ReasonNoDrift,
+	Message: MessageNoDrift,
+}
+
+func SetDriftedNone(conditions *[]metav1.Condition, generation int64) {
+	condition := *Drifted.DeepCopy()
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetDriftedCorrected(conditions *[]metav1.Condition, generation int64) {
+	condition := *Drifted.DeepCopy()
+	condition.Reason = ReasonDriftCorrected
+	condition.Message = MessageDriftCorrected
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetDriftedReported(conditions *[]metav1.Condition, generation int64, count int) {
+	condition := *Drifted.DeepCopy()
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonDriftReported
+	condition.Message = fmt.Sprintf("The volume group on the node differs from the spec in %d operation(s), "+
+		"which are reported in the status and not corrected due to the drift policy.", count)
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeReserveViolated is a condition type that indicates whether less capacity is free in the volume group
+	// than reserved by the spec.
+	ConditionTypeReserveViolated = "ReserveViolated"
+	ReasonReserveAvailable       = "ReserveAvailable"
+	ReasonReserveViolated        = "ReserveViolated"
+	MessageReserveAvailable      = "The reserved capacity is free in the volume group."
+)
+
+var ReserveViolated = metav1.Condition{
+	Type:    ConditionTypeReserveViolated,
+	Status:  metav1.ConditionFalse,
+	Reason:  ReasonReserveAvailable,
+	Message: MessageReserveAvailable,
+}
+
+func SetReserveAvailable(conditions *[]metav1.Condition, generation int64) {
+	condition := *ReserveViolated.DeepCopy()
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetReserveViolated(conditions *[]metav1.Condition, generation int64, free, reserved *resource.Quantity) {
+	condition := *ReserveViolated.DeepCopy()
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonReserveViolated
+	condition.Message = fmt.Sprintf("Only %s are free in the volume group, but %s are reserved.",
+		free.String(), reserved.String())
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -1,20 +1,32 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/topolvm/topovgm/internal/lvmerr"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	// ConditionTypeVolumeGroupSyncedOnNode is a condition type that indicates whether the volume group is present on the host node.
-	ConditionTypeVolumeGroupSyncedOnNode = "VolumeGroupSyncedOnNode"
-	ReasonVolumeGroupSynced              = "VolumeGroupSynced"
-	ReasonVolumeGroupSyncFailed          = "VolumeGroupSyncFailed"
-	ReasonVolumeGroupSyncPending         = "VolumeGroupSyncPending"
-	MessageVolumeGroupSyncPending        = "The volume group is waiting to be synchronized with the node."
-	MessageVolumeGroupCreated            = "The volume group is present on the node and discoverable in the lvm2 subsystem."
+	ConditionTypeVolumeGroupSyncedOnNode   = "VolumeGroupSyncedOnNode"
+	ReasonVolumeGroupSynced                = "VolumeGroupSynced"
+	ReasonVolumeGroupSyncFailed            = "VolumeGroupSyncFailed"
+	ReasonVolumeGroupSyncFailedTransiently = "VolumeGroupSyncFailedTransiently"
+	ReasonVolumeGroupSyncFailedTerminally  = "VolumeGroupSyncFailedTerminally"
+	ReasonVolumeGroupOperationTimedOut     = "VolumeGroupOperationTimedOut"
+	ReasonVolumeGroupSyncPending           = "VolumeGroupSyncPending"
+	ReasonPhysicalVolumeMoveInProgress     = "PhysicalVolumeMoveInProgress"
+	ReasonOperationsPlanned                = "OperationsPlanned"
+	ReasonVolumeGroupExported              = "VolumeGroupExported"
+	ReasonWaitingForDeviceReturn           = "WaitingForDeviceReturn"
+	MessageVolumeGroupSyncPending          = "The volume group is waiting to be synchronized with the node."
+	MessageVolumeGroupCreated              = "The volume group is present on the node and discoverable in the lvm2 subsystem."
+	MessagePhysicalVolumeMoveInProgress    = "Extents are being moved off physical volumes before they are removed from the volume group."
+	MessageVolumeGroupExported             = "The volume group is exported on the node and can be imported on another node."
 )
 
 var SyncedOnHost = metav1.Condition{
@@ -32,7 +44,17 @@ func SetSyncedOnHostDefault(conditions *[]metav1.Condition, generation int64) {
 
 func SetSyncedOnHostCreationFailed(conditions *[]metav1.Condition, generation int64, err error) {
 	condition := *SyncedOnHost.DeepCopy()
-	condition.Reason = ReasonVolumeGroupSyncFailed
+	var timeout *TimeoutError
+	switch {
+	case errors.As(err, &timeout):
+		condition.Reason = ReasonVolumeGroupOperationTimedOut
+	case lvmerr.IsTerminal(err):
+		condition.Reason = ReasonVolumeGroupSyncFailedTerminally
+	case lvmerr.IsTransient(err):
+		condition.Reason = ReasonVolumeGroupSyncFailedTransiently
+	default:
+		condition.Reason = ReasonVolumeGroupSyncFailed
+	}
 	condition.Message = fmt.Sprintf("volume group creation failed: %s", err.Error())
 	condition.ObservedGeneration = generation
 	meta.SetStatusCondition(conditions, condition)
@@ -46,3 +68,45 @@ func SetSyncedOnHostCreationOK(conditions *[]metav1.Condition, generation int64)
 	condition.ObservedGeneration = generation
 	meta.SetStatusCondition(conditions, condition)
 }
+
+func SetSyncedOnHostMoveInProgress(conditions *[]metav1.Condition, generation int64) {
+	condition := *SyncedOnHost.DeepCopy()
+	condition.Reason = ReasonPhysicalVolumeMoveInProgress
+	condition.Message = MessagePhysicalVolumeMoveInProgress
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetSyncedOnHostExported(conditions *[]metav1.Condition, generation int64) {
+	condition := *SyncedOnHost.DeepCopy()
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonVolumeGroupExported
+	condition.Message = MessageVolumeGroupExported
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetSyncedOnHostPlanned(conditions *[]metav1.Condition, generation int64, count int) {
+	condition := *SyncedOnHost.DeepCopy()
+	condition.Reason = ReasonOperationsPlanned
+	condition.Message = fmt.Sprintf("The volume group is in plan mode, %d operation(s) are planned and not run on the node.", count)
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// IsSyncedOnHostFailedTerminally returns true if the last sync of the given generation failed terminally.
+func IsSyncedOnHostFailedTerminally(conditions []metav1.Condition, generation int64) bool {
+	condition := meta.FindStatusCondition(conditions, ConditionTypeVolumeGroupSyncedOnNode)
+	return condition != nil &&
+		condition.Reason == ReasonVolumeGroupSyncFailedTerminally &&
+		condition.ObservedGeneration == generation
+}
+
+func SetSyncedOnHostWaitingForDeviceReturn(conditions *[]metav1.Condition, generation int64, missing int, deadline time.Time) {
+	condition := *SyncedOnHost.DeepCopy()
+	condition.Reason = ReasonWaitingForDeviceReturn
+	condition.Message = fmt.Sprintf("%d physical volume(s) are missing. Waiting for them to return until %s, "+
+		"before they are removed from the volume group.", missing, deadline.UTC().Format(time.RFC3339))
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeThinPoolShrinkRefused is a condition type that indicates whether the spec requests thin pools
+	// smaller than on the node, which is refused as thin pools cannot be shrunk.
+	ConditionTypeThinPoolShrinkRefused = "ThinPoolShrinkRefused"
+	ReasonNoShrink                     = "NoShrink"
+	ReasonShrinkRefused                = "ShrinkRefused"
+	MessageNoShrink                    = "No thin pool is requested smaller than on the node."
+)
+
+var ThinPoolShrinkRefused = metav1.Condition{
+	Type:    ConditionTypeThinPoolShrinkRefused,
+	Status:  metav1.ConditionFalse,
+	Reason:  ReasonNoShrink,
+	Message: MessageNoShrink,
+}
+
+func SetThinPoolShrinkRefusedNone(conditions *[]metav1.Condition, generation int64) {
+	condition := *ThinPoolShrinkRefused.DeepCopy()
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func SetThinPoolShrinkRefused(conditions *[]metav1.Condition, generation int64, pools []string) {
+	condition := *ThinPoolShrinkRefused.DeepCopy()
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonShrinkRefused
+	condition.Message = fmt.Sprintf("Thin pools cannot be shrunk, but the spec requests a smaller size or metadata size "+
+		"for %s. Increase the size in the spec to at least the size on the node.", strings.Join(pools, ", "))
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+}
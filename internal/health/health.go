@@ -0,0 +1,148 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+)
+
+// DefaultSysfsRoot is the mount point of sysfs.
+const DefaultSysfsRoot = "/sys"
+
+// Report is the health of a block device derived from the signals available on the node.
+type Report struct {
+	// IOErrors is the number of I/O errors of the device since boot, summed up over the devices below a
+	// device-mapper device. Corresponds to ioerr_cnt of SCSI devices in sysfs.
+	IOErrors int64
+	// Suspended is true if the device is a suspended device-mapper device.
+	Suspended bool
+	// SMARTPassed is the result of the SMART overall health self-assessment, which is nil if it is not available.
+	SMARTPassed *bool
+}
+
+// Monitor reads the health signals of block devices from sysfs and optionally SMART.
+type Monitor struct {
+	// SysfsRoot is the mount point of sysfs, e.g. a directory with fixtures in tests.
+	SysfsRoot string
+	// Smartctl is the path of smartctl on the node. If empty, SMART is not checked.
+	Smartctl string
+}
+
+// Check returns the health of the block device with the path and the device number.
+func (m Monitor) Check(ctx context.Context, path string, major, minor int64) (Report, error) {
+	// dev/block/<major>:<minor> links to the directory of the device below devices,
+	// which is resolved so that the disk of a partition is found in its parent directory.
+	dir, err := filepath.EvalSymlinks(filepath.Join(m.SysfsRoot, "dev", "block", fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to find device %s in sysfs: %w", path, err)
+	}
+
+	report := Report{}
+	if report.IOErrors, err = ioErrors(dir); err != nil {
+		return Report{}, fmt.Errorf("failed to read I/O errors of device %s: %w", path, err)
+	}
+	if report.Suspended, err = suspended(dir); err != nil {
+		return Report{}, fmt.Errorf("failed to read device-mapper state of device %s: %w", path, err)
+	}
+	// SMART is only available for physical devices.
+	if m.Smartctl != "" && !isDeviceMapper(dir) {
+		if report.SMARTPassed, err = m.smart(ctx, path); err != nil {
+			return Report{}, fmt.Errorf("failed to read SMART status of device %s: %w", path, err)
+		}
+	}
+	return report, nil
+}
+
+// ioErrors returns the I/O errors of the device in the sysfs directory. The errors of a partition are the ones of
+// its disk, and the errors of a device-mapper device are the sum of the errors of its underlying devices.
+func ioErrors(dir string) (int64, error) {
+	if isDeviceMapper(dir) {
+		slaves, err := os.ReadDir(filepath.Join(dir, "slaves"))
+		if err != nil {
+			return 0, err
+		}
+		var sum int64
+		for _, slave := range slaves {
+			slaveDir, err := filepath.EvalSymlinks(filepath.Join(dir, "slaves", slave.Name()))
+			if err != nil {
+				return 0, err
+			}
+			count, err := ioErrors(slaveDir)
+			if err != nil {
+				return 0, err
+			}
+			sum += count
+		}
+		return sum, nil
+	}
+
+	for _, file := range []string{
+		filepath.Join(dir, "device", "ioerr_cnt"),
+		filepath.Join(dir, "..", "device", "ioerr_cnt"),
+	} {
+		content, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		// ioerr_cnt is reported in hexadecimal, e.g. 0x1a.
+		return strconv.ParseInt(strings.TrimSpace(string(content)), 0, 64)
+	}
+	// Devices other than SCSI devices, e.g. NVMe or virtio devices, do not count I/O errors in sysfs.
+	return 0, nil
+}
+
+// suspended returns true if the device in the sysfs directory is a suspended device-mapper device.
+func suspended(dir string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "dm", "suspended"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(content)) == "1", nil
+}
+
+func isDeviceMapper(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "dm"))
+	return err == nil
+}
+
+// smart returns the result of the SMART overall health self-assessment of the device.
+func (m Monitor) smart(ctx context.Context, path string) (*bool, error) {
+	// smartctl reports problems of the device in its exit status, so it is ignored and only the report is decoded.
+	output, err := lvmcmd.RunCommand(ctx, nil, lvmcmd.ShCommand, "-c", `"$1" --health --json "$2"; true`, "sh", m.Smartctl, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSMART(output)
+}
+
+// parseSMART parses the JSON output of smartctl --health.
+func parseSMART(output []byte) (*bool, error) {
+	// smartctl is not installed on the node or printed nothing.
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var report struct {
+		SMARTStatus *struct {
+			Passed bool `json:"passed"`
+		} `json:"smart_status"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode smartctl output: %w", err)
+	}
+	if report.SMARTStatus == nil {
+		return nil, nil
+	}
+	return &report.SMARTStatus.Passed, nil
+}
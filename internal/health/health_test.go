@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSysfs writes the files relative to the root of a fake sysfs.
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// link links the device directory as dev/block/<major>:<minor> in a fake sysfs.
+func link(t *testing.T, root, device, number string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, "dev", "block"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, device), filepath.Join(root, "dev", "block", number)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMonitorCheck(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"block/sda/device/ioerr_cnt": "0x1a\n",
+		"block/sda/sda1/partition":   "1\n",
+		"block/sdb/device/ioerr_cnt": "0x0\n",
+		"block/vda/size":             "2048\n",
+		"block/dm-0/dm/suspended":    "1\n",
+		"block/dm-1/dm/suspended":    "0\n",
+	})
+	link(t, root, "block/sda", "8:0")
+	link(t, root, "block/sda/sda1", "8:1")
+	link(t, root, "block/sdb", "8:16")
+	link(t, root, "block/vda", "252:0")
+	link(t, root, "block/dm-0", "253:0")
+	link(t, root, "block/dm-1", "253:1")
+	for _, slave := range []struct{ dm, device string }{{"dm-0", "sda"}, {"dm-1", "sdb"}} {
+		if err := os.MkdirAll(filepath.Join(root, "block", slave.dm, "slaves"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(root, "block", slave.device),
+			filepath.Join(root, "block", slave.dm, "slaves", slave.device)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	monitor := Monitor{SysfsRoot: root}
+	for _, tc := range []struct {
+		name         string
+		major, minor int64
+		expected     Report
+	}{
+		{name: "scsi disk with errors", major: 8, minor: 0, expected: Report{IOErrors: 26}},
+		{name: "partition of disk with errors", major: 8, minor: 1, expected: Report{IOErrors: 26}},
+		{name: "scsi disk without errors", major: 8, minor: 16, expected: Report{}},
+		{name: "disk without error counter", major: 252, minor: 0, expected: Report{}},
+		{name: "suspended device-mapper device", major: 253, minor: 0, expected: Report{IOErrors: 26, Suspended: true}},
+		{name: "active device-mapper device", major: 253, minor: 1, expected: Report{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := monitor.Check(context.Background(), tc.name, tc.major, tc.minor)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report != tc.expected {
+				t.Fatalf("expected report %+v, got %+v", tc.expected, report)
+			}
+		})
+	}
+
+	if _, err := monitor.Check(context.Background(), "missing", 1, 1); err == nil {
+		t.Fatal("expected error for device missing in sysfs")
+	}
+}
+
+func TestParseSMART(t *testing.T) {
+	for _, tc := range []struct {
+		name, output string
+		expected     *bool
+	}{
+		{name: "passed", output: `{"smart_status":{"passed":true}}`, expected: ptr(true)},
+		{name: "failed", output: `{"smart_status":{"passed":false}}`, expected: ptr(false)},
+		{name: "unavailable", output: `{"smartctl":{"exit_status":4}}`},
+		{name: "not installed", output: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			passed, err := parseSMART([]byte(tc.output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (passed == nil) != (tc.expected == nil) || passed != nil && *passed != *tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, passed)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,98 @@
+package hostevents
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultLVMBackupDir is the directory lvm2 writes a backup of the metadata of a volume group to
+// after every change of its metadata (see backup/backup_dir in lvm.conf(5)).
+const DefaultLVMBackupDir = "/etc/lvm/backup"
+
+// MetadataWatcher watches the lvm2 metadata backup directory of the host and emits the name of a volume group
+// whenever its metadata changes, no matter if the change was made by the controller or outside of it.
+type MetadataWatcher struct {
+	dir     string
+	events  chan event.TypedGenericEvent[string]
+	running atomic.Bool
+}
+
+// NewMetadataWatcher creates a MetadataWatcher for the given lvm2 metadata backup directory.
+func NewMetadataWatcher(dir string) *MetadataWatcher {
+	return &MetadataWatcher{
+		dir:    dir,
+		events: make(chan event.TypedGenericEvent[string]),
+	}
+}
+
+// Events returns the channel the names of changed volume groups are emitted on.
+func (w *MetadataWatcher) Events() <-chan event.TypedGenericEvent[string] {
+	return w.events
+}
+
+// Covers returns true if the watcher is running and lvm2 writes metadata backups for the volume group.
+func (w *MetadataWatcher) Covers(name string) bool {
+	if !w.running.Load() {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(w.dir, name))
+	return err == nil
+}
+
+// Start watches the backup directory until the context is cancelled.
+func (w *MetadataWatcher) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("metadata-watcher").WithValues("dir", w.dir)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher for lvm2 metadata backups: %w", err)
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			logger.Error(err, "failed to close watcher for lvm2 metadata backups")
+		}
+	}()
+
+	if err := watcher.Add(w.dir); err != nil {
+		return fmt.Errorf("failed to watch lvm2 metadata backups in %s: %w", w.dir, err)
+	}
+
+	w.running.Store(true)
+	defer w.running.Store(false)
+	logger.Info("watching lvm2 metadata backups for changes of volume groups on the host")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Error(err, "error while watching lvm2 metadata backups")
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Base(ev.Name)
+			// lvm2 writes the backup to a hidden temporary file first and then renames it.
+			if strings.HasPrefix(name, ".") || ev.Has(fsnotify.Chmod) {
+				continue
+			}
+			logger.V(1).Info("lvm2 metadata backup changed", "vg", name, "op", ev.Op.String())
+			select {
+			case w.events <- event.TypedGenericEvent[string]{Object: name}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
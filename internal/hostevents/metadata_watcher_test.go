@@ -0,0 +1,64 @@
+package hostevents
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetadataWatcher(t *testing.T) {
+	dir := t.TempDir()
+	watcher := NewMetadataWatcher(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- watcher.Start(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !watcher.running.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if watcher.Covers("vg1") {
+		t.Fatal("expected vg1 not to be covered before a backup exists")
+	}
+
+	// simulate lvm2 writing a backup through a hidden temporary file
+	tmp := filepath.Join(dir, ".lvm_host_1_2")
+	if err := os.WriteFile(tmp, []byte("vg1 {}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "vg1")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ev := <-watcher.Events():
+		if ev.Object != "vg1" {
+			t.Fatalf("unexpected event for %q", ev.Object)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received for vg1")
+	}
+
+	if !watcher.Covers("vg1") {
+		t.Fatal("expected vg1 to be covered once a backup exists")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if watcher.Covers("vg1") {
+		t.Fatal("expected vg1 not to be covered after the watcher stopped")
+	}
+}
@@ -0,0 +1,173 @@
+package luks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topovgm/internal/lsblk"
+	"github.com/topolvm/topovgm/internal/lvmcmd"
+)
+
+const cryptsetupCommand = "/usr/sbin/cryptsetup"
+const readlinkCommand = "/usr/bin/readlink"
+
+// fsTypeLUKS is the filesystem type lsblk reports for LUKS devices.
+const fsTypeLUKS = "crypto_LUKS"
+
+// typeCrypt is the device type lsblk reports for dm-crypt mappings.
+const typeCrypt = "crypt"
+
+// Device is the LUKS state of a block device.
+type Device struct {
+	// Path is the path of the block device.
+	Path string
+	// UUID is the UUID of the LUKS header, which is empty if the device is not formatted with LUKS.
+	UUID string
+	// Mapping is the path of the open dm-crypt mapping of the device, which is empty if it is not open.
+	Mapping string
+	// FSType is the signature lsblk reports on the device, e.g. a filesystem or LVM2_member.
+	FSType string
+	// Partitioned is true if the device has partitions or other devices stacked on top of it.
+	Partitioned bool
+}
+
+// Blank returns true if the device carries no signature and no devices are stacked on top of it,
+// which is the only state a device is formatted with LUKS in.
+func (d Device) Blank() bool {
+	return d.FSType == "" && !d.Partitioned
+}
+
+// MappingName returns the name of the dm-crypt mapping of the LUKS device with the UUID.
+func MappingName(uuid string) string {
+	return "topovgm-" + uuid
+}
+
+// MappingPath returns the path of the dm-crypt mapping of the LUKS device with the UUID.
+func MappingPath(uuid string) string {
+	return "/dev/mapper/" + MappingName(uuid)
+}
+
+// Devices returns the LUKS state of the block devices with the paths, which may be symlinks to the devices.
+// Paths that are no block device are missing from the result.
+func Devices(ctx context.Context, paths []string) (map[string]Device, error) {
+	devices, err := lsblk.LSBLK(ctx, lsblk.ColumnPath, lsblk.ColumnFSType, lsblk.ColumnUUID, lsblk.ColumnType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block devices: %w", err)
+	}
+	blockDevices := lsblk.RecursiveBlockDevices(devices)
+	result := devicesFromBlockDevices(blockDevices, paths)
+	for _, path := range paths {
+		if _, ok := result[path]; ok {
+			continue
+		}
+		// lsblk reports the canonical path of a device, so symlinks such as /dev/disk/by-id/... are resolved first.
+		canonical, err := canonicalPath(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if device, ok := devicesFromBlockDevices(blockDevices, []string{canonical})[canonical]; ok {
+			device.Path = path
+			result[path] = device
+		}
+	}
+	return result, nil
+}
+
+// canonicalPath resolves the symlinks of the path on the host.
+func canonicalPath(ctx context.Context, path string) (string, error) {
+	output, err := lvmcmd.RunCommand(ctx, nil, readlinkCommand, "-f", "--", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve device path %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// devicesFromBlockDevices returns the LUKS state of the block devices with the paths from the output of lsblk.
+func devicesFromBlockDevices(blockDevices []lsblk.BlockDevice, paths []string) map[string]Device {
+	result := make(map[string]Device, len(paths))
+	for _, dev := range blockDevices {
+		path, _ := dev.GetString(lsblk.ColumnPath)
+		if !slices.Contains(paths, path) {
+			continue
+		}
+		device := Device{Path: path}
+		device.FSType, _ = dev.GetString(lsblk.ColumnFSType)
+		if device.FSType == fsTypeLUKS {
+			device.UUID, _ = dev.GetString(lsblk.ColumnUUID)
+		}
+		device.Partitioned = len(dev.Children()) > 0
+		for _, child := range dev.Children() {
+			if typ, _ := child.GetString(lsblk.ColumnType); typ != typeCrypt || device.UUID == "" {
+				continue
+			}
+			if mapping, _ := child.GetString(lsblk.ColumnPath); mapping == MappingPath(device.UUID) {
+				device.Mapping = mapping
+			}
+		}
+		result[path] = device
+	}
+	return result
+}
+
+// Format formats the device with LUKS2 using the UUID and the key. All data on the device is lost.
+func Format(ctx context.Context, device, uuid string, key []byte) error {
+	if err := run(ctx, key, cryptsetupCommand, "luksFormat", "--batch-mode", "--type", "luks2",
+		"--uuid", uuid, "--key-file", "-", device); err != nil {
+		return fmt.Errorf("failed to format device %s with LUKS: %w", device, err)
+	}
+	return nil
+}
+
+// Open opens the dm-crypt mapping of the LUKS device with the UUID using the key.
+func Open(ctx context.Context, device, uuid string, key []byte) error {
+	if err := run(ctx, key, cryptsetupCommand, "open", "--type", "luks2", "--key-file", "-",
+		device, MappingName(uuid)); err != nil {
+		return fmt.Errorf("failed to open LUKS device %s: %w", device, err)
+	}
+	return nil
+}
+
+// Close closes the dm-crypt mapping of the LUKS device with the UUID.
+func Close(ctx context.Context, uuid string) error {
+	if err := run(ctx, nil, cryptsetupCommand, "close", MappingName(uuid)); err != nil {
+		return fmt.Errorf("failed to close LUKS mapping %s: %w", MappingName(uuid), err)
+	}
+	return nil
+}
+
+// TestKey checks whether the key opens the LUKS device without opening a mapping.
+// Any failure of cryptsetup, including a wrong key, is reported as an error.
+func TestKey(ctx context.Context, device string, key []byte) error {
+	if err := run(ctx, key, cryptsetupCommand, "open", "--test-passphrase", "--key-file", "-", device); err != nil {
+		return fmt.Errorf("key does not open LUKS device %s: %w", device, err)
+	}
+	return nil
+}
+
+// RotateKey adds the new key to the LUKS device and removes the old key afterward, so that the device
+// can be opened with at least one of the keys at any time and no data is lost.
+func RotateKey(ctx context.Context, device string, oldKey, newKey []byte) error {
+	// cryptsetup reads only one key from stdin, so the new key is passed in a file. It is written to a private
+	// directory on tmpfs that is removed once the shell exits, so it is neither predictable nor kept on disk.
+	// The new key is read byte by byte from stdin, so that cryptsetup reads exactly the old key following it.
+	script := `dir="$(mktemp -d -p /run)" && trap 'rm -rf "$dir"' EXIT && ` +
+		`dd of="$dir/key" bs=1 count="$2" status=none && "$1" luksAddKey --batch-mode --key-file - "$3" "$dir/key"`
+	input := append(slices.Clone(newKey), oldKey...)
+	if err := run(ctx, input, lvmcmd.ShCommand, "-c", script, "sh",
+		cryptsetupCommand, strconv.Itoa(len(newKey)), device); err != nil {
+		return fmt.Errorf("failed to add new key to LUKS device %s: %w", device, err)
+	}
+	if err := run(ctx, oldKey, cryptsetupCommand, "luksRemoveKey", "--batch-mode", "--key-file", "-", device); err != nil {
+		return fmt.Errorf("failed to remove old key from LUKS device %s: %w", device, err)
+	}
+	return nil
+}
+
+// run calls the command on the host with the input and discards its output.
+func run(ctx context.Context, input []byte, command string, args ...string) error {
+	_, err := lvmcmd.RunCommand(ctx, input, command, args...)
+	return err
+}
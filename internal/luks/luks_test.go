@@ -0,0 +1,95 @@
+package luks
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jakobmoellerdev/lvm2go"
+	"github.com/topolvm/topovgm/internal/lsblk"
+)
+
+func TestDevicesFromBlockDevices(t *testing.T) {
+	uuid := "0b6f8b8e-5d1c-4f0e-9a53-3f0b0c7f5e21"
+	blockDevices := lsblk.RecursiveBlockDevices([]lsblk.BlockDevice{
+		{"path": "/dev/sda", "fstype": "crypto_LUKS", "uuid": uuid, "type": "disk", "children": []any{
+			map[string]any{"path": MappingPath(uuid), "fstype": "LVM2_member", "type": "crypt"},
+		}},
+		{"path": "/dev/sdb", "fstype": "crypto_LUKS", "uuid": "closed", "type": "disk"},
+		{"path": "/dev/sdc", "type": "disk"},
+		{"path": "/dev/sdd", "type": "disk"},
+		{"path": "/dev/sde", "fstype": "LVM2_member", "type": "disk"},
+		{"path": "/dev/sdf", "type": "disk", "children": []any{
+			map[string]any{"path": "/dev/sdf1", "fstype": "xfs", "type": "part"},
+		}},
+	})
+
+	devices := devicesFromBlockDevices(blockDevices, []string{"/dev/sda", "/dev/sdb", "/dev/sdc", "/dev/sde", "/dev/sdf"})
+	for path, expected := range map[string]Device{
+		"/dev/sda": {Path: "/dev/sda", UUID: uuid, Mapping: MappingPath(uuid), FSType: fsTypeLUKS, Partitioned: true},
+		"/dev/sdb": {Path: "/dev/sdb", UUID: "closed", FSType: fsTypeLUKS},
+		"/dev/sdc": {Path: "/dev/sdc"},
+		"/dev/sde": {Path: "/dev/sde", FSType: "LVM2_member"},
+		"/dev/sdf": {Path: "/dev/sdf", Partitioned: true},
+	} {
+		if devices[path] != expected {
+			t.Errorf("expected device %v, got %v", expected, devices[path])
+		}
+	}
+	for path, blank := range map[string]bool{"/dev/sdc": true, "/dev/sde": false, "/dev/sdf": false} {
+		if devices[path].Blank() != blank {
+			t.Errorf("expected device %s to be blank=%t", path, blank)
+		}
+	}
+	if _, ok := devices["/dev/sdd"]; ok {
+		t.Errorf("unexpected device /dev/sdd")
+	}
+}
+
+func TestFormatOpenRotateClose(t *testing.T) {
+	if _, err := os.Stat(cryptsetupCommand); err != nil {
+		t.Skipf("%s is not available: %v", cryptsetupCommand, err)
+	}
+	ctx := context.Background()
+
+	device, err := lvm2go.NewLoopbackDevice(lvm2go.MustParseSize("32M"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := device.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	uuid := "6a2a3c3e-1d2b-4c5d-8e9f-0a1b2c3d4e5f"
+	oldKey, newKey := []byte("old-passphrase"), []byte("new-passphrase")
+	if err := Format(ctx, device.Device(), uuid, oldKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(ctx, device.Device(), uuid, oldKey); err != nil {
+		t.Fatal(err)
+	}
+
+	devices, err := Devices(ctx, []string{device.Device()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Device{Path: device.Device(), UUID: uuid, Mapping: MappingPath(uuid), FSType: fsTypeLUKS, Partitioned: true}); devices[device.Device()] != expected {
+		t.Fatalf("expected device %v, got %v", expected, devices[device.Device()])
+	}
+
+	if err := RotateKey(ctx, device.Device(), oldKey, newKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := TestKey(ctx, device.Device(), newKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := TestKey(ctx, device.Device(), oldKey); err == nil {
+		t.Fatal("expected old key to be removed")
+	}
+
+	if err := Close(ctx, uuid); err != nil {
+		t.Fatal(err)
+	}
+}
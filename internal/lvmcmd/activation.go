@@ -0,0 +1,46 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Activation is the activation of logical volumes passed to vgchange -a, see vgchange(8).
+type Activation string
+
+const (
+	Activate            Activation = "y"
+	ActivateExclusively Activation = "ey"
+	ActivateShared      Activation = "sy"
+	Deactivate          Activation = "n"
+)
+
+// LogicalVolumeActivation counts the logical volumes in the volume group and how many of them are active,
+// based on the state in their attributes. Hidden logical volumes, e.g. of pvmove or thin pools, are not counted.
+func LogicalVolumeActivation(ctx context.Context, vg string) (total, active int, err error) {
+	type lv struct {
+		Name string `json:"lv_name"`
+		Attr string `json:"lv_attr"`
+	}
+	lvs, err := RunReport[lv](ctx, "lv", "lvs", "-o", "lv_name,lv_attr", vg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list logical volumes in volume group %s: %w", vg, err)
+	}
+
+	for _, lv := range lvs {
+		total++
+		// The fifth attribute is the state of the logical volume, which is 'a' if it is active.
+		if len(lv.Attr) > 4 && lv.Attr[4] == 'a' {
+			active++
+		}
+	}
+	return total, active, nil
+}
+
+// ChangeActivation activates or deactivates all logical volumes in the volume group.
+func ChangeActivation(ctx context.Context, vg string, activation Activation) error {
+	if err := Run(ctx, "vgchange", "-a"+string(activation), vg); err != nil {
+		return fmt.Errorf("failed to change activation of volume group %s to %s: %w", vg, activation, err)
+	}
+	return nil
+}
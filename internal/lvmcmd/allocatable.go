@@ -0,0 +1,18 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeAllocatable changes whether extents can be allocated on the physical volume.
+func ChangeAllocatable(ctx context.Context, pv string, allocatable bool) error {
+	value := "n"
+	if allocatable {
+		value = "y"
+	}
+	if err := Run(ctx, "pvchange", "--allocatable", value, pv); err != nil {
+		return fmt.Errorf("failed to change allocatable of physical volume %s to %s: %w", pv, value, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package lvmcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// SequenceNumber returns the sequence number of the metadata of the volume group, which is increased on every change.
+func SequenceNumber(ctx context.Context, vg string) (int64, error) {
+	type report struct {
+		SeqNo string `json:"vg_seqno"`
+	}
+	vgs, err := RunReport[report](ctx, "vg", "vgs", "-o", "vg_seqno", vg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sequence number of volume group %s: %w", vg, err)
+	}
+	if len(vgs) == 0 {
+		return 0, fmt.Errorf("failed to get sequence number of volume group %s: not reported", vg)
+	}
+	return strconv.ParseInt(vgs[0].SeqNo, 10, 64)
+}
+
+// Backup returns a backup of the metadata of the volume group taken with vgcfgbackup.
+func Backup(ctx context.Context, vg string) ([]byte, error) {
+	script := withBackupDir(`"$1" vgcfgbackup -f "$dir/backup" "$2" >/dev/null && cat "$dir/backup"`)
+	output, err := runCommand(ctx, nil, ShCommand, "-c", script, "sh", lvmCommand, vg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up metadata of volume group %s: %w", vg, err)
+	}
+	backup, err := io.ReadAll(output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return nil, fmt.Errorf("failed to back up metadata of volume group %s: %w", vg, err)
+	}
+	return backup, nil
+}
+
+// Restore restores the metadata of the volume group from a backup taken with Backup using vgcfgrestore.
+func Restore(ctx context.Context, vg string, backup []byte) error {
+	script := withBackupDir(`cat > "$dir/backup" && "$1" vgcfgrestore -f "$dir/backup" "$2" >/dev/null`)
+	output, err := runCommand(ctx, bytes.NewReader(backup), ShCommand, "-c", script, "sh", lvmCommand, vg)
+	if err != nil {
+		return fmt.Errorf("failed to restore metadata of volume group %s: %w", vg, err)
+	}
+	_, err = io.Copy(io.Discard, output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return fmt.Errorf("failed to restore metadata of volume group %s: %w", vg, err)
+	}
+	return nil
+}
+
+// withBackupDir runs the script with a private temporary directory on the host in $dir, which is removed afterward.
+// vgcfgbackup and vgcfgrestore only work with files, and a directory created by mktemp cannot be tampered with
+// by other users of the host, unlike a predictable file in /tmp.
+func withBackupDir(script string) string {
+	return `dir="$(mktemp -d)" && trap 'rm -rf "$dir"' EXIT && ` + script
+}
@@ -0,0 +1,141 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CacheMode is the mode of a cache attached to a logical volume, see lvmcache(7).
+type CacheMode string
+
+const (
+	CacheModeWritethrough CacheMode = "writethrough"
+	CacheModeWriteback    CacheMode = "writeback"
+	// CacheModeWritecache attaches the cache with dm-writecache instead of dm-cache.
+	CacheModeWritecache CacheMode = "writecache"
+)
+
+// LogicalVolumeCache is a logical volume as reported by lvs together with the statistics of its cache, if any.
+type LogicalVolumeCache struct {
+	Name string
+	Tags []string
+	// Mode is the mode of the cache, which is empty if the logical volume is not cached.
+	Mode CacheMode
+	// CacheVolume is the name of the cache volume attached to the logical volume.
+	CacheVolume string
+
+	ReadHits    int64
+	ReadMisses  int64
+	WriteHits   int64
+	WriteMisses int64
+	DirtyBlocks int64
+}
+
+// LogicalVolumeCaches returns the logical volumes in the volume group with the statistics of their caches.
+func LogicalVolumeCaches(ctx context.Context, vg string) ([]LogicalVolumeCache, error) {
+	type lv struct {
+		Name                     string `json:"lv_name"`
+		Tags                     string `json:"lv_tags"`
+		SegType                  string `json:"segtype"`
+		PoolLV                   string `json:"pool_lv"`
+		CacheMode                string `json:"cache_mode"`
+		CacheReadHits            string `json:"cache_read_hits"`
+		CacheReadMisses          string `json:"cache_read_misses"`
+		CacheWriteHits           string `json:"cache_write_hits"`
+		CacheWriteMisses         string `json:"cache_write_misses"`
+		CacheDirtyBlocks         string `json:"cache_dirty_blocks"`
+		WritecacheWritebackBlock string `json:"writecache_writeback_blocks"`
+	}
+	lvs, err := RunReport[lv](ctx, "lv", "lvs", "-o", "lv_name,lv_tags,segtype,pool_lv,cache_mode,"+
+		"cache_read_hits,cache_read_misses,cache_write_hits,cache_write_misses,cache_dirty_blocks,writecache_writeback_blocks", vg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list caches in volume group %s: %w", vg, err)
+	}
+
+	caches := make([]LogicalVolumeCache, 0, len(lvs))
+	for _, lv := range lvs {
+		cache := LogicalVolumeCache{Name: lv.Name}
+		if lv.Tags != "" {
+			cache.Tags = strings.Split(lv.Tags, ",")
+		}
+		switch lv.SegType {
+		case "cache":
+			cache.Mode = CacheMode(lv.CacheMode)
+			cache.DirtyBlocks = parseCount(lv.CacheDirtyBlocks)
+		case "writecache":
+			cache.Mode = CacheModeWritecache
+			cache.DirtyBlocks = parseCount(lv.WritecacheWritebackBlock)
+		default:
+			caches = append(caches, cache)
+			continue
+		}
+		cache.CacheVolume = strings.Trim(lv.PoolLV, "[]")
+		cache.ReadHits = parseCount(lv.CacheReadHits)
+		cache.ReadMisses = parseCount(lv.CacheReadMisses)
+		cache.WriteHits = parseCount(lv.CacheWriteHits)
+		cache.WriteMisses = parseCount(lv.CacheWriteMisses)
+		caches = append(caches, cache)
+	}
+	return caches, nil
+}
+
+// CreateCacheVolume creates a cache volume with the size in bytes on the physical volumes with the tag.
+func CreateCacheVolume(ctx context.Context, vg, name string, size int64, pvTag string) error {
+	if err := Run(ctx, "lvcreate", "--yes", "--activate", "n", "--name", name,
+		"--size", fmt.Sprintf("%db", size), vg, "@"+pvTag); err != nil {
+		return fmt.Errorf("failed to create cache volume %s in volume group %s: %w", name, vg, err)
+	}
+	return nil
+}
+
+// AttachCache attaches the cache volume to the logical volume in the mode.
+func AttachCache(ctx context.Context, vg, lv, cacheVolume string, mode CacheMode) error {
+	args := []string{"lvconvert", "--yes"}
+	if mode == CacheModeWritecache {
+		args = append(args, "--type", "writecache")
+	} else {
+		args = append(args, "--type", "cache", "--cachemode", string(mode))
+	}
+	args = append(args, "--cachevol", vg+"/"+cacheVolume, vg+"/"+lv)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to attach cache volume %s to logical volume %s in volume group %s: %w", cacheVolume, lv, vg, err)
+	}
+	return nil
+}
+
+// SplitCache detaches the cache volume from the logical volume and keeps it, so that it can be attached again.
+// Blocks of a writeback cache that were not written to the origin yet are written back first.
+func SplitCache(ctx context.Context, vg, lv string) error {
+	if err := Run(ctx, "lvconvert", "--yes", "--splitcache", vg+"/"+lv); err != nil {
+		return fmt.Errorf("failed to detach cache from logical volume %s in volume group %s: %w", lv, vg, err)
+	}
+	return nil
+}
+
+// ChangeCacheMode changes the mode of the dm-cache attached to the logical volume.
+func ChangeCacheMode(ctx context.Context, vg, lv string, mode CacheMode) error {
+	if err := Run(ctx, "lvchange", "--cachemode", string(mode), vg+"/"+lv); err != nil {
+		return fmt.Errorf("failed to change cache mode of logical volume %s in volume group %s to %s: %w", lv, vg, mode, err)
+	}
+	return nil
+}
+
+// AddPhysicalVolumeTags adds the tags to the physical volume.
+func AddPhysicalVolumeTags(ctx context.Context, pv string, tags []string) error {
+	args := []string{"pvchange"}
+	for _, tag := range tags {
+		args = append(args, "--addtag", tag)
+	}
+	if err := Run(ctx, append(args, pv)...); err != nil {
+		return fmt.Errorf("failed to add tags %v to physical volume %s: %w", tags, pv, err)
+	}
+	return nil
+}
+
+// parseCount parses a counter reported by lvs, which is empty if not available.
+func parseCount(value string) int64 {
+	count, _ := strconv.ParseInt(value, 10, 64)
+	return count
+}
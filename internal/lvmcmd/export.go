@@ -0,0 +1,42 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// VolumeGroupNameByUUID returns the name of the volume group with the UUID on the node,
+// including exported volume groups. It returns an empty name if no volume group has the UUID.
+func VolumeGroupNameByUUID(ctx context.Context, uuid string) (string, error) {
+	type vg struct {
+		Name string `json:"vg_name"`
+		UUID string `json:"vg_uuid"`
+	}
+	vgs, err := RunReport[vg](ctx, "vg", "vgs", "-o", "vg_name,vg_uuid")
+	if err != nil {
+		return "", fmt.Errorf("failed to list volume groups: %w", err)
+	}
+	for _, vg := range vgs {
+		if vg.UUID == uuid {
+			return vg.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// Export exports the volume group with vgexport, so that its physical volumes can be moved to another node.
+// All logical volumes in the volume group have to be inactive.
+func Export(ctx context.Context, vg string) error {
+	if err := Run(ctx, "vgexport", vg); err != nil {
+		return fmt.Errorf("failed to export volume group %s: %w", vg, err)
+	}
+	return nil
+}
+
+// Import imports the exported volume group with vgimport, so that it can be used on the node.
+func Import(ctx context.Context, vg string) error {
+	if err := Run(ctx, "vgimport", vg); err != nil {
+		return fmt.Errorf("failed to import volume group %s: %w", vg, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package lvmcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const lvmlockctlCommand = "/usr/sbin/lvmlockctl"
+
+// LockType returns the lock type of the volume group, e.g. sanlock or dlm, or none for local volume groups.
+func LockType(ctx context.Context, vg string) (string, error) {
+	type report struct {
+		LockType string `json:"vg_lock_type"`
+	}
+	vgs, err := RunReport[report](ctx, "vg", "vgs", "-o", "vg_lock_type", vg)
+	if err != nil {
+		return "", fmt.Errorf("failed to get lock type of volume group %s: %w", vg, err)
+	}
+	if len(vgs) == 0 || vgs[0].LockType == "" {
+		return "none", nil
+	}
+	return vgs[0].LockType, nil
+}
+
+// ChangeLockType changes the lock type of the volume group, e.g. to convert a local volume group into a
+// shared volume group. All logical volumes in the volume group have to be inactive.
+func ChangeLockType(ctx context.Context, vg, lockType string) error {
+	if err := Run(ctx, "vgchange", "--locktype", lockType, vg); err != nil {
+		return fmt.Errorf("failed to change lock type of volume group %s to %s: %w", vg, lockType, err)
+	}
+	return nil
+}
+
+// StartLock starts the lockspace of the shared volume group in lvmlockd, which is required to use it on the node.
+func StartLock(ctx context.Context, vg string) error {
+	if err := Run(ctx, "vgchange", "--lockstart", vg); err != nil {
+		return fmt.Errorf("failed to start lockspace of volume group %s: %w", vg, err)
+	}
+	return nil
+}
+
+// StopLock stops the lockspace of the shared volume group in lvmlockd.
+// All logical volumes in the volume group have to be inactive on the node.
+func StopLock(ctx context.Context, vg string) error {
+	if err := Run(ctx, "vgchange", "--lockstop", vg); err != nil {
+		return fmt.Errorf("failed to stop lockspace of volume group %s: %w", vg, err)
+	}
+	return nil
+}
+
+// LockStarted returns true if the lockspace of the shared volume group is started in lvmlockd on the node.
+func LockStarted(ctx context.Context, vg string) (bool, error) {
+	output, err := runCommand(ctx, nil, lvmlockctlCommand, "--info")
+	if err != nil {
+		return false, fmt.Errorf("failed to get lockspaces from lvmlockd: %w", err)
+	}
+	started, err := parseStartedLockspaces(output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return false, fmt.Errorf("failed to get lockspaces from lvmlockd: %w", err)
+	}
+	_, ok := started[vg]
+	return ok, nil
+}
+
+// parseStartedLockspaces parses the output of lvmlockctl --info and returns the names of all volume groups
+// whose lockspace is started, e.g. from lines like:
+//
+//	info=ls ls_name=lvm_vg1 vg_name=vg1 vg_uuid=... lm_type=sanlock host_id=1 create_fail=0 create_done=1 ...
+func parseStartedLockspaces(r io.Reader) (map[string]struct{}, error) {
+	started := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "info=ls" {
+			continue
+		}
+		values := make(map[string]string, len(fields))
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				values[key] = value
+			}
+		}
+		if values["vg_name"] != "" && values["create_done"] == "1" && values["thread_stop"] != "1" {
+			started[values["vg_name"]] = struct{}{}
+		}
+	}
+	return started, scanner.Err()
+}
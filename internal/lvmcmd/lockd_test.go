@@ -0,0 +1,25 @@
+package lvmcmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStartedLockspaces(t *testing.T) {
+	info := `info=global_lockspace_exists 1
+info=ls ls_name=lvm_vg1 vg_name=vg1 vg_uuid=abc vg_sysid=. vg_args=1.0.0:lvmlock lm_type=sanlock host_id=1 create_fail=0 create_done=1 thread_work=0 thread_stop=0 thread_done=0 kill_vg=0 drop_vg=0 sanlock_gl_enabled=1
+info=r name=VGLK type=vg mode=un sh_count=0 version=3
+info=ls ls_name=lvm_vg2 vg_name=vg2 vg_uuid=def vg_sysid=. vg_args=1.0.0:lvmlock lm_type=sanlock host_id=1 create_fail=0 create_done=0 thread_work=1 thread_stop=0 thread_done=0 kill_vg=0 drop_vg=0
+info=ls ls_name=lvm_vg3 vg_name=vg3 vg_uuid=ghi vg_sysid=. vg_args=1.0.0:lvmlock lm_type=dlm host_id=1 create_fail=0 create_done=1 thread_work=0 thread_stop=1 thread_done=0 kill_vg=0 drop_vg=0
+`
+	started, err := parseStartedLockspaces(strings.NewReader(info))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(started) != 1 {
+		t.Fatalf("expected only vg1 to be started, got %v", started)
+	}
+	if _, ok := started["vg1"]; !ok {
+		t.Fatalf("expected vg1 to be started, got %v", started)
+	}
+}
@@ -0,0 +1,97 @@
+package lvmcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/jakobmoellerdev/lvm2go"
+)
+
+const lvmCommand = "/usr/sbin/lvm"
+const nsenterCommand = "/usr/bin/nsenter"
+
+// ShCommand is the shell of the host, which runs scripts passed to RunCommand with -c.
+const ShCommand = "/usr/bin/sh"
+
+// Run calls the lvm sub-command with the provided arguments and discards its output.
+// It is used for lvm2 operations that are not covered by lvm2go.
+func Run(ctx context.Context, args ...string) error {
+	output, err := run(ctx, args...)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, output)
+	return errors.Join(err, output.Close())
+}
+
+// RunReport calls the lvm reporting sub-command with the provided arguments in JSON report format and
+// decodes all entries of the report with the given key (e.g. "lv", "pv", "vg" or "pvseg").
+func RunReport[T any](ctx context.Context, key string, args ...string) ([]T, error) {
+	output, err := run(ctx, append(args, "--reportformat", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeReport[T](output, key)
+	return entries, errors.Join(err, output.Close())
+}
+
+// decodeReport decodes the JSON report format of lvm2 and returns the entries of the report with the given key.
+func decodeReport[T any](r io.Reader, key string) ([]T, error) {
+	var report struct {
+		Report []map[string][]T `json:"report"`
+	}
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode lvm report: %w", err)
+	}
+	var entries []T
+	for _, r := range report.Report {
+		entries = append(entries, r[key]...)
+	}
+	return entries, nil
+}
+
+// RunCommand calls the command on the host with the optional input and returns its output.
+// It is used for commands other than lvm that need to run on the host, e.g. cryptsetup or smartctl.
+func RunCommand(ctx context.Context, input []byte, command string, args ...string) ([]byte, error) {
+	var stdin io.Reader
+	if input != nil {
+		stdin = bytes.NewReader(input)
+	}
+	output, err := runCommand(ctx, stdin, command, args...)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, output)
+	return buf.Bytes(), errors.Join(err, output.Close())
+}
+
+// run calls the lvm sub-command and returns its streamed output.
+func run(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	return runCommand(ctx, nil, lvmCommand, args...)
+}
+
+// runCommand calls the command on the host with the optional input and returns its streamed output.
+func runCommand(ctx context.Context, input io.Reader, command string, args ...string) (io.ReadCloser, error) {
+	var cmd *exec.Cmd
+
+	if lvm2go.IsContainerized(ctx) {
+		args = append([]string{"-m", "-u", "-i", "-n", "-p", "-t", "1", command}, args...)
+		cmd = exec.CommandContext(ctx, nsenterCommand, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, command, args...)
+	}
+	cmd.Env = append(cmd.Env, "LC_ALL=C")
+	cmd.Stdin = input
+
+	output, err := lvm2go.StreamedCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command: %w", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,41 @@
+package lvmcmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeReport(t *testing.T) {
+	type pvseg struct {
+		PVName string `json:"pv_name"`
+		Start  string `json:"pvseg_start"`
+		Size   string `json:"pvseg_size"`
+		LVName string `json:"lv_name"`
+	}
+
+	report := `{
+      "report": [
+          {
+              "pvseg": [
+                  {"pv_name":"/dev/loop0", "pvseg_start":"0", "pvseg_size":"25", "lv_name":"lvol0"},
+                  {"pv_name":"/dev/loop0", "pvseg_start":"25", "pvseg_size":"100", "lv_name":""}
+              ]
+          }
+      ]
+  }`
+
+	segments, err := decodeReport[pvseg](strings.NewReader(report), "pvseg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("unexpected segments: %v", segments)
+	}
+	if segments[0].LVName != "lvol0" || segments[1].Start != "25" {
+		t.Fatalf("unexpected segments: %v", segments)
+	}
+
+	if entries, err := decodeReport[pvseg](strings.NewReader(report), "lv"); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries for unknown key, got %v (err: %v)", entries, err)
+	}
+}
@@ -0,0 +1,50 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// metadataCopiesUnmanaged is reported and accepted by lvm2 for volume groups without a managed number of metadata copies.
+const metadataCopiesUnmanaged = "unmanaged"
+
+// MetadataCopies returns the number of metadata copies the volume group keeps, or 0 if it is unmanaged.
+func MetadataCopies(ctx context.Context, vg string) (int64, error) {
+	type report struct {
+		MetadataCopies string `json:"vg_mda_copies"`
+	}
+	vgs, err := RunReport[report](ctx, "vg", "vgs", "-o", "vg_mda_copies", vg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get metadata copies of volume group %s: %w", vg, err)
+	}
+	if len(vgs) == 0 || vgs[0].MetadataCopies == metadataCopiesUnmanaged || vgs[0].MetadataCopies == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(vgs[0].MetadataCopies, 10, 64)
+}
+
+// ChangeMetadataCopies changes the number of metadata copies the volume group keeps, where 0 means unmanaged.
+func ChangeMetadataCopies(ctx context.Context, vg string, copies int64) error {
+	value := metadataCopiesUnmanaged
+	if copies > 0 {
+		value = strconv.FormatInt(copies, 10)
+	}
+	if err := Run(ctx, "vgchange", "--vgmetadatacopies", value, vg); err != nil {
+		return fmt.Errorf("failed to change metadata copies of volume group %s to %s: %w", vg, value, err)
+	}
+	return nil
+}
+
+// ChangeMetadataIgnore changes whether the metadata areas of the physical volumes are ignored.
+func ChangeMetadataIgnore(ctx context.Context, pvs []string, ignore bool) error {
+	value := "n"
+	if ignore {
+		value = "y"
+	}
+	args := append([]string{"pvchange", "--metadataignore", value}, pvs...)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to change metadata ignore of physical volumes %v to %s: %w", pvs, value, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package lvmcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultProfileDir is the directory lvm2 loads profiles from unless configured otherwise with config/profile_dir.
+const DefaultProfileDir = "/etc/lvm/profile"
+
+// ProfileDir returns the directory lvm2 loads profiles from on the host.
+func ProfileDir(ctx context.Context) (string, error) {
+	output, err := run(ctx, "lvmconfig", "--typeconfig", "full", "config/profile_dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to get profile directory of host: %w", err)
+	}
+	dir, err := parseProfileDir(output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return "", fmt.Errorf("failed to get profile directory of host: %w", err)
+	}
+	return dir, nil
+}
+
+// InstalledProfile returns the content of the profile installed in the profile directory of the host.
+// It returns no content if the profile is not installed.
+func InstalledProfile(ctx context.Context, dir, name string) ([]byte, error) {
+	output, err := runCommand(ctx, nil, ShCommand, "-c", `test ! -e "$1" || cat "$1"`, "sh", profileFile(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+	content, err := io.ReadAll(output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// InstallProfile writes the profile into the profile directory of the host, replacing an installed profile atomically.
+func InstallProfile(ctx context.Context, dir, name string, content []byte) error {
+	file := profileFile(dir, name)
+	output, err := runCommand(ctx, bytes.NewReader(content), ShCommand,
+		"-c", `mkdir -p "$1" && cat > "$2.tmp" && mv "$2.tmp" "$2"`, "sh", dir, file)
+	if err != nil {
+		return fmt.Errorf("failed to install profile %s: %w", name, err)
+	}
+	_, err = io.Copy(io.Discard, output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return fmt.Errorf("failed to install profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// MetadataProfile returns the name of the metadata profile attached to the volume group, if any.
+func MetadataProfile(ctx context.Context, vg string) (string, error) {
+	type report struct {
+		Profile string `json:"vg_profile"`
+	}
+	vgs, err := RunReport[report](ctx, "vg", "vgs", "-o", "vg_profile", vg)
+	if err != nil {
+		return "", fmt.Errorf("failed to get metadata profile of volume group %s: %w", vg, err)
+	}
+	if len(vgs) == 0 {
+		return "", nil
+	}
+	return vgs[0].Profile, nil
+}
+
+// ChangeMetadataProfile attaches the metadata profile to the volume group.
+func ChangeMetadataProfile(ctx context.Context, vg, name string) error {
+	if err := Run(ctx, "vgchange", "--metadataprofile", name, vg); err != nil {
+		return fmt.Errorf("failed to attach metadata profile %s to volume group %s: %w", name, vg, err)
+	}
+	return nil
+}
+
+// profileFile is the file a profile with the name is loaded from by lvm2.
+func profileFile(dir, name string) string {
+	return filepath.Join(dir, name+".profile")
+}
+
+// parseProfileDir parses the output of lvmconfig config/profile_dir, e.g. `profile_dir="/etc/lvm/profile"`.
+func parseProfileDir(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if _, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "profile_dir="); ok {
+			return strconv.Unquote(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return DefaultProfileDir, nil
+}
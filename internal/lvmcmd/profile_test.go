@@ -0,0 +1,26 @@
+package lvmcmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProfileDir(t *testing.T) {
+	for _, tc := range []struct {
+		name, output, expected string
+	}{
+		{name: "configured", output: "profile_dir=\"/etc/lvm/custom\"\n", expected: "/etc/lvm/custom"},
+		{name: "indented", output: "  profile_dir=\"/etc/lvm/profile\"\n", expected: "/etc/lvm/profile"},
+		{name: "empty output", output: "", expected: DefaultProfileDir},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := parseProfileDir(strings.NewReader(tc.output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dir != tc.expected {
+				t.Fatalf("expected profile directory %q, got %q", tc.expected, dir)
+			}
+		})
+	}
+}
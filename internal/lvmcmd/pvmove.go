@@ -0,0 +1,113 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Move is a pvmove that is currently in progress in a volume group.
+// It is represented in lvm2 by a temporary, hidden logical volume.
+type Move struct {
+	// LogicalVolume is the name of the temporary logical volume created by pvmove.
+	LogicalVolume string
+	// Source is the physical volume that extents are moved off.
+	Source string
+	// CopyPercent is the progress of the move in percent.
+	CopyPercent float64
+}
+
+// Segment is a contiguous range of extents on a physical volume.
+type Segment struct {
+	// PhysicalVolume is the name of the physical volume the segment is located on.
+	PhysicalVolume string
+	// Start is the first extent of the segment.
+	Start int64
+	// Size is the number of extents in the segment.
+	Size int64
+	// LogicalVolume is the name of the logical volume the segment is allocated to.
+	// It is empty if the segment is free.
+	LogicalVolume string
+}
+
+// Allocated returns true if the segment is allocated to a logical volume.
+func (s Segment) Allocated() bool {
+	return s.LogicalVolume != ""
+}
+
+// Moves lists all moves that are currently in progress in the volume group.
+func Moves(ctx context.Context, vg string) ([]Move, error) {
+	type lv struct {
+		Name        string `json:"lv_name"`
+		MovePV      string `json:"move_pv"`
+		CopyPercent string `json:"copy_percent"`
+	}
+	lvs, err := RunReport[lv](ctx, "lv", "lvs", "-a", "-o", "lv_name,move_pv,copy_percent", vg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moves in volume group %s: %w", vg, err)
+	}
+
+	var moves []Move
+	for _, lv := range lvs {
+		if lv.MovePV == "" {
+			continue
+		}
+		move := Move{LogicalVolume: lv.Name, Source: lv.MovePV}
+		if lv.CopyPercent != "" {
+			if move.CopyPercent, err = strconv.ParseFloat(lv.CopyPercent, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse copy percent of %s: %w", lv.Name, err)
+			}
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// Segments lists all segments of the physical volume, including free ones.
+func Segments(ctx context.Context, pv string) ([]Segment, error) {
+	type pvseg struct {
+		PVName string `json:"pv_name"`
+		Start  string `json:"pvseg_start"`
+		Size   string `json:"pvseg_size"`
+		LVName string `json:"lv_name"`
+	}
+	pvsegs, err := RunReport[pvseg](ctx, "pvseg", "pvs", "--segments", "-o", "pv_name,pvseg_start,pvseg_size,lv_name", pv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments of physical volume %s: %w", pv, err)
+	}
+
+	segments := make([]Segment, 0, len(pvsegs))
+	for _, seg := range pvsegs {
+		segment := Segment{PhysicalVolume: seg.PVName, LogicalVolume: seg.LVName}
+		if segment.Start, err = strconv.ParseInt(seg.Start, 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse segment start of %s: %w", pv, err)
+		}
+		if segment.Size, err = strconv.ParseInt(seg.Size, 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse segment size of %s: %w", pv, err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// StartMove starts a pvmove in the background that moves the extents from start to end (inclusive) off the
+// source physical volume onto the destinations. The call returns as soon as the move was started;
+// its progress can be observed with Moves.
+// As lvm2 keeps track of the move in the volume group metadata, an interrupted move can be resumed with ResumeMoves.
+func StartMove(ctx context.Context, source string, start, end int64, destinations []string) error {
+	args := []string{"pvmove", "--background", fmt.Sprintf("%s:%d-%d", source, start, end)}
+	args = append(args, destinations...)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to start moving extents %d-%d off %s: %w", start, end, source, err)
+	}
+	return nil
+}
+
+// ResumeMoves resumes polling all interrupted moves on the host in the background,
+// e.g. after a restart of the host or the process that started them.
+func ResumeMoves(ctx context.Context) error {
+	if err := Run(ctx, "pvmove", "--background"); err != nil {
+		return fmt.Errorf("failed to resume interrupted moves: %w", err)
+	}
+	return nil
+}
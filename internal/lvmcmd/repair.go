@@ -0,0 +1,83 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PartialLogicalVolume is a logical volume with extents on a missing physical volume.
+type PartialLogicalVolume struct {
+	Name        string
+	SegmentType string
+}
+
+// Repairable returns true if the logical volume keeps redundant copies of its data and can be repaired
+// onto another physical volume with lvconvert --repair, see lvmraid(7). raid0 stripes without redundancy.
+func (lv PartialLogicalVolume) Repairable() bool {
+	if lv.SegmentType == "raid0" || strings.HasPrefix(lv.SegmentType, "raid0_") {
+		return false
+	}
+	return strings.HasPrefix(lv.SegmentType, "raid") || lv.SegmentType == "mirror"
+}
+
+// PartialLogicalVolumes returns the logical volumes in the volume group with extents on missing physical volumes.
+func PartialLogicalVolumes(ctx context.Context, vg string) ([]PartialLogicalVolume, error) {
+	type lv struct {
+		Name         string `json:"lv_name"`
+		SegType      string `json:"segtype"`
+		HealthStatus string `json:"lv_health_status"`
+	}
+	lvs, err := RunReport[lv](ctx, "lv", "lvs", "-o", "lv_name,segtype,lv_health_status", vg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partial logical volumes in volume group %s: %w", vg, err)
+	}
+	var partial []PartialLogicalVolume
+	for _, lv := range lvs {
+		if lv.HealthStatus == "partial" {
+			partial = append(partial, PartialLogicalVolume{Name: lv.Name, SegmentType: lv.SegType})
+		}
+	}
+	return partial, nil
+}
+
+// RepairLogicalVolume replaces the images of the logical volume on missing physical volumes with new images
+// allocated on the given physical volumes.
+func RepairLogicalVolume(ctx context.Context, vg, lv string, pvs ...string) error {
+	args := append([]string{"lvconvert", "--repair", "--yes", vg + "/" + lv}, pvs...)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to repair logical volume %s/%s: %w", vg, lv, err)
+	}
+	return nil
+}
+
+// PhysicalVolumeState is a physical volume of a volume group, which might be missing.
+type PhysicalVolumeState struct {
+	Name    string
+	UUID    string
+	Missing bool
+}
+
+// PhysicalVolumeStates returns the physical volumes of the volume group including missing physical volumes,
+// which are reported with the name [unknown]. With an empty volume group, all physical volumes of the host are returned.
+// Unlike listing physical volumes with lvm2go, missing physical volumes are not reported as an error.
+func PhysicalVolumeStates(ctx context.Context, vg string) ([]PhysicalVolumeState, error) {
+	type pv struct {
+		Name    string `json:"pv_name"`
+		UUID    string `json:"pv_uuid"`
+		Missing string `json:"pv_missing"`
+	}
+	args := []string{"pvs", "-o", "pv_name,pv_uuid,pv_missing"}
+	if vg != "" {
+		args = append(args, "--select", "vg_name="+vg)
+	}
+	pvs, err := RunReport[pv](ctx, "pv", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list physical volumes of volume group %s: %w", vg, err)
+	}
+	states := make([]PhysicalVolumeState, 0, len(pvs))
+	for _, pv := range pvs {
+		states = append(states, PhysicalVolumeState{Name: pv.Name, UUID: pv.UUID, Missing: pv.Missing == "missing"})
+	}
+	return states, nil
+}
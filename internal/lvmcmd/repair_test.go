@@ -0,0 +1,21 @@
+package lvmcmd
+
+import "testing"
+
+func TestPartialLogicalVolumeRepairable(t *testing.T) {
+	for segType, repairable := range map[string]bool{
+		"raid1":      true,
+		"raid5_n":    true,
+		"raid10":     true,
+		"raid0":      false,
+		"raid0_meta": false,
+		"mirror":     true,
+		"linear":     false,
+		"striped":    false,
+		"thin":       false,
+	} {
+		if got := (PartialLogicalVolume{SegmentType: segType}).Repairable(); got != repairable {
+			t.Errorf("expected %s to be repairable=%t, got %t", segType, repairable, got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package lvmcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HostSystemID returns the system ID of the node as configured in lvm.conf(5), see lvmsystemid(7).
+// It returns an empty system ID if the node has none.
+func HostSystemID(ctx context.Context) (string, error) {
+	output, err := run(ctx, "systemid")
+	if err != nil {
+		return "", fmt.Errorf("failed to get system ID of host: %w", err)
+	}
+	id, err := parseSystemID(output)
+	if err = errors.Join(err, output.Close()); err != nil {
+		return "", fmt.Errorf("failed to get system ID of host: %w", err)
+	}
+	return id, nil
+}
+
+// ChangeSystemID changes the system ID of the volume group.
+// extraSystemIDs allows changing the system ID of a foreign volume group owned by one of them, e.g. to take it over.
+func ChangeSystemID(ctx context.Context, vg, systemID string, extraSystemIDs ...string) error {
+	args := []string{"vgchange", "--yes", "--systemid", systemID}
+	if len(extraSystemIDs) > 0 {
+		args = append(args, "--config", fmt.Sprintf(`local/extra_system_ids=["%s"]`, strings.Join(extraSystemIDs, `","`)))
+	}
+	args = append(args, vg)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to change system ID of volume group %s to %s: %w", vg, systemID, err)
+	}
+	return nil
+}
+
+// ForeignSystemID returns the system ID of the volume group, including volume groups that are foreign to the node
+// because they are owned by another host. It returns false if no volume group with the name exists.
+func ForeignSystemID(ctx context.Context, vg string) (string, bool, error) {
+	type report struct {
+		Name     string `json:"vg_name"`
+		SystemID string `json:"vg_systemid"`
+	}
+	vgs, err := RunReport[report](ctx, "vg", "vgs", "--foreign", "-o", "vg_name,vg_systemid")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list foreign volume groups: %w", err)
+	}
+	for _, entry := range vgs {
+		if entry.Name == vg {
+			return entry.SystemID, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// parseSystemID parses the output of lvm systemid, e.g. "  system ID: host1".
+func parseSystemID(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if _, id, ok := strings.Cut(scanner.Text(), "system ID:"); ok {
+			return strings.TrimSpace(id), nil
+		}
+	}
+	return "", scanner.Err()
+}
@@ -0,0 +1,26 @@
+package lvmcmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSystemID(t *testing.T) {
+	for _, tc := range []struct {
+		name, output, expected string
+	}{
+		{name: "system ID", output: "  system ID: node-1\n", expected: "node-1"},
+		{name: "no system ID", output: "  system ID: \n", expected: ""},
+		{name: "empty output", output: "", expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := parseSystemID(strings.NewReader(tc.output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tc.expected {
+				t.Fatalf("expected system ID %q, got %q", tc.expected, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package lvmcmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// ThinPool is a thin pool logical volume as reported by lvs.
+type ThinPool struct {
+	Name string
+	// Size is the size of the data of the thin pool in bytes.
+	Size int64
+	// MetadataSize is the size of the metadata of the thin pool in bytes.
+	MetadataSize int64
+	// ChunkSize is the chunk size of the thin pool in bytes.
+	ChunkSize int64
+	// DataPercent is the percentage of the data of the thin pool in use, e.g. "12.50".
+	DataPercent string
+	// MetadataPercent is the percentage of the metadata of the thin pool in use, e.g. "1.20".
+	MetadataPercent string
+	// Profile is the name of the metadata profile attached to the thin pool, if any.
+	Profile string
+}
+
+// ThinPools returns the thin pools in the volume group.
+func ThinPools(ctx context.Context, vg string) ([]ThinPool, error) {
+	type lv struct {
+		Name            string `json:"lv_name"`
+		Size            string `json:"lv_size"`
+		MetadataSize    string `json:"lv_metadata_size"`
+		ChunkSize       string `json:"chunk_size"`
+		DataPercent     string `json:"data_percent"`
+		MetadataPercent string `json:"metadata_percent"`
+		Profile         string `json:"lv_profile"`
+	}
+	lvs, err := RunReport[lv](ctx, "lv", "lvs",
+		"-o", "lv_name,lv_size,lv_metadata_size,chunk_size,data_percent,metadata_percent,lv_profile",
+		"--units", "b", "--nosuffix", "-S", "segtype=thin-pool", vg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list thin pools in volume group %s: %w", vg, err)
+	}
+
+	pools := make([]ThinPool, 0, len(lvs))
+	for _, lv := range lvs {
+		pool := ThinPool{
+			Name:            lv.Name,
+			DataPercent:     lv.DataPercent,
+			MetadataPercent: lv.MetadataPercent,
+			Profile:         lv.Profile,
+		}
+		for field, value := range map[*int64]string{
+			&pool.Size:         lv.Size,
+			&pool.MetadataSize: lv.MetadataSize,
+			&pool.ChunkSize:    lv.ChunkSize,
+		} {
+			if value == "" {
+				continue
+			}
+			if *field, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse size of thin pool %s: %w", lv.Name, err)
+			}
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// CreateThinPool creates a thin pool with the number of extents for its data in the volume group.
+// The chunk size and the metadata size in bytes are chosen by lvm2 if 0.
+func CreateThinPool(ctx context.Context, vg, name string, extents, chunkSize, metadataSize int64) error {
+	args := []string{"lvcreate", "--type", "thin-pool", "--name", name, "--extents", strconv.FormatInt(extents, 10)}
+	if chunkSize > 0 {
+		args = append(args, "--chunksize", fmt.Sprintf("%db", chunkSize))
+	}
+	if metadataSize > 0 {
+		args = append(args, "--poolmetadatasize", fmt.Sprintf("%db", metadataSize))
+	}
+	args = append(args, vg)
+	if err := Run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to create thin pool %s in volume group %s: %w", name, vg, err)
+	}
+	return nil
+}
+
+// ExtendThinPool extends the data of the thin pool to the number of extents.
+func ExtendThinPool(ctx context.Context, vg, name string, extents int64) error {
+	if err := Run(ctx, "lvextend", "--extents", strconv.FormatInt(extents, 10), vg+"/"+name); err != nil {
+		return fmt.Errorf("failed to extend thin pool %s in volume group %s: %w", name, vg, err)
+	}
+	return nil
+}
+
+// ExtendThinPoolMetadata extends the metadata of the thin pool to the size in bytes.
+func ExtendThinPoolMetadata(ctx context.Context, vg, name string, metadataSize int64) error {
+	if err := Run(ctx, "lvextend", "--poolmetadatasize", fmt.Sprintf("%db", metadataSize), vg+"/"+name); err != nil {
+		return fmt.Errorf("failed to extend metadata of thin pool %s in volume group %s: %w", name, vg, err)
+	}
+	return nil
+}
+
+// ChangeLogicalVolumeProfile attaches the metadata profile to the logical volume.
+func ChangeLogicalVolumeProfile(ctx context.Context, vg, lv, profile string) error {
+	if err := Run(ctx, "lvchange", "--metadataprofile", profile, vg+"/"+lv); err != nil {
+		return fmt.Errorf("failed to attach metadata profile %s to logical volume %s in volume group %s: %w", profile, lv, vg, err)
+	}
+	return nil
+}
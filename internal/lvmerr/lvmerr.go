@@ -0,0 +1,158 @@
+package lvmerr
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Class classifies an error returned by lvm2 (or by preparing an lvm2 operation) by how it should be retried.
+type Class int
+
+const (
+	// Unknown errors are retried with the default backoff.
+	Unknown Class = iota
+	// Transient errors are expected to resolve themselves, e.g. lock contention or busy devices.
+	// They are retried with backoff.
+	Transient
+	// Terminal errors cannot be resolved by retrying the same operation, e.g. an invalid volume group name
+	// or a device that is already part of another volume group. They are not retried until the spec changes.
+	Terminal
+)
+
+func (c Class) String() string {
+	switch c {
+	case Transient:
+		return "Transient"
+	case Terminal:
+		return "Terminal"
+	default:
+		return "Unknown"
+	}
+}
+
+// terminalMessages match lvm2 error messages that indicate a terminal error. They are anchored to the specific
+// messages, as generic wording such as "already exists" or "invalid argument" also occurs in errors that resolve
+// themselves, e.g. a logical volume created concurrently or a failed ioctl.
+var terminalMessages = []*regexp.Regexp{
+	// New volume group name "x" is invalid.
+	regexp.MustCompile(`volume group name "[^"]*" is invalid`),
+	// Volume group name "x" has invalid characters.
+	regexp.MustCompile(`volume group name "[^"]*" has invalid characters`),
+	// Physical volume '/dev/x' is already in volume group 'y'
+	regexp.MustCompile(`physical volume '[^']*' is already in volume group`),
+	// A volume group called x already exists.
+	regexp.MustCompile(`a volume group called \S+ already exists`),
+	// Cannot use /dev/x: device is excluded by a filter
+	regexp.MustCompile(`device is excluded by a filter`),
+	// Invalid argument for --x: y
+	regexp.MustCompile(`invalid argument for --`),
+	// No space for '/dev/x' - volume group 'y' holds max n physical volume(s).
+	regexp.MustCompile(`volume group '[^']*' holds max \d+ physical volume`),
+	// Physical extent size must be a multiple of 8 sectors. / Physical extent size must be a power of 2.
+	regexp.MustCompile(`physical extent size must be a (multiple of|power of 2)`),
+	// Physical extent size cannot be larger than 16 GiB.
+	regexp.MustCompile(`physical extent size cannot be larger than`),
+}
+
+// transientMessages are substrings of lvm2 error messages that indicate a transient error.
+var transientMessages = []string{
+	"can't get lock",
+	"cannot get lock",
+	"could not get lock",
+	"failed to get lock",
+	"giving up waiting for lock",
+	"device or resource busy",
+	"resource temporarily unavailable",
+	"exclusively",           // Can't open /dev/x exclusively. Mounted filesystem?
+	"is in use",             // Device /dev/x is in use
+	"is currently in use",   // see above
+	"connection refused",    // lvmpolld / lvmlockd are not reachable (yet)
+	"interrupted by signal", // command was interrupted
+}
+
+// terminalError marks an error as terminal regardless of its message.
+type terminalError struct {
+	err error
+}
+
+func (e *terminalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *terminalError) Unwrap() error {
+	return e.err
+}
+
+// NewTerminal marks err as terminal. It can be used for errors detected before lvm2 is called,
+// e.g. a physical volume selector that does not match any device.
+func NewTerminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &terminalError{err: err}
+}
+
+// Classify classifies err by how it should be retried.
+// Joined errors are classified by the least severe error contained in them:
+// they are only terminal if all contained errors are terminal, and only transient
+// if none of the contained errors is unknown.
+func Classify(err error) Class {
+	switch err := err.(type) {
+	case nil:
+		return Unknown
+	case *terminalError:
+		return Terminal
+	case interface{ Unwrap() []error }:
+		// Classes are ordered by severity, so the least severe class of all joined errors is the minimum.
+		class, found := Terminal, false
+		for _, err := range err.Unwrap() {
+			if err != nil {
+				class, found = min(class, Classify(err)), true
+			}
+		}
+		if !found {
+			return Unknown
+		}
+		return class
+	case interface{ Unwrap() error }:
+		inner := err.Unwrap()
+		if class := Classify(inner); class != Unknown {
+			return class
+		}
+		// The message of a wrapped join contains the messages of all joined errors,
+		// so it must not be matched again after the join was classified as unknown.
+		if _, joined := inner.(interface{ Unwrap() []error }); joined {
+			return Unknown
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return Transient
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, transient := range transientMessages {
+		if strings.Contains(msg, transient) {
+			return Transient
+		}
+	}
+	for _, terminal := range terminalMessages {
+		if terminal.MatchString(msg) {
+			return Terminal
+		}
+	}
+
+	return Unknown
+}
+
+// IsTerminal returns true if err is classified as Terminal.
+func IsTerminal(err error) bool {
+	return Classify(err) == Terminal
+}
+
+// IsTransient returns true if err is classified as Transient.
+func IsTransient(err error) bool {
+	return Classify(err) == Transient
+}
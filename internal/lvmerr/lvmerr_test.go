@@ -0,0 +1,61 @@
+package lvmerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, Unknown},
+		{"unknown", errors.New("something unexpected happened"), Unknown},
+		{"invalid name", errors.New(`New volume group name "-vg" is invalid.`), Terminal},
+		{"device in other vg", errors.New(`Physical volume '/dev/loop0' is already in volume group 'vg1'`), Terminal},
+		{"existing vg", errors.New(`A volume group called vg1 already exists.`), Terminal},
+		{"invalid option", errors.New(`Invalid argument for --size: 10X`), Terminal},
+		{"max pvs", errors.New(`No space for '/dev/loop1' - volume group 'vg1' holds max 1 physical volume(s).`), Terminal},
+		{"extent size multiple", errors.New(`Physical extent size must be a multiple of 8 sectors.`), Terminal},
+		{"extent size power of 2", errors.New(`Physical extent size must be a power of 2.`), Terminal},
+		{"existing lv", errors.New(`Logical volume "pool" already exists in volume group "vg1"`), Unknown},
+		{"ioctl", errors.New(`device-mapper: reload ioctl on (253:3) failed: Invalid argument`), Unknown},
+		{"invalid metadata", errors.New(`Metadata on /dev/loop0 at 4608 is invalid.`), Unknown},
+		{"explicitly terminal", NewTerminal(errors.New("selector did not match any device")), Terminal},
+		{"wrapped terminal", fmt.Errorf("failed: %w", NewTerminal(errors.New("no devices"))), Terminal},
+		{"lock contention", errors.New(`Giving up waiting for lock.`), Transient},
+		{"busy device", errors.New(`Can't open /dev/loop0 exclusively.  Mounted filesystem?`), Transient},
+		{"deadline", fmt.Errorf("vgcreate: %w", context.DeadlineExceeded), Transient},
+		{"joined terminal", errors.Join(
+			NewTerminal(errors.New("no devices")),
+			errors.New(`A volume group called vg1 already exists.`),
+		), Terminal},
+		{"joined terminal and transient", errors.Join(
+			NewTerminal(errors.New("no devices")),
+			errors.New("Device or resource busy"),
+			nil,
+		), Transient},
+		{"joined with unknown", errors.Join(
+			errors.New("Device or resource busy"),
+			errors.New("something unexpected happened"),
+		), Unknown},
+		{"wrapped join", fmt.Errorf("sync failed: %w", errors.Join(
+			NewTerminal(errors.New("no devices")),
+			errors.New("something unexpected happened"),
+		)), Unknown},
+		{"wrapped join with terminal message", fmt.Errorf("sync failed: %w", errors.Join(
+			errors.New(`A volume group called vg1 already exists.`),
+			errors.New("something unexpected happened"),
+		)), Unknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.err); got != tc.want {
+				t.Errorf("Classify(%v) = %s, want %s", tc.err, got, tc.want)
+			}
+		})
+	}
+}
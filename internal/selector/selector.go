@@ -14,6 +14,26 @@ import (
 var runLSBLK = lsblk.LSBLK
 
 func DevicesMatchingSelector(ctx context.Context, selector v1alpha1.PhysicalVolumeSelector) ([]string, error) {
+	terms, err := DevicesMatchingTerms(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, devices := range terms {
+		selected = append(selected, devices...)
+	}
+
+	slices.Sort(selected)
+	// Remove duplicate matches
+	selected = slices.Compact(selected)
+
+	return selected, nil
+}
+
+// DevicesMatchingTerms returns the devices matching each term of the selector, in the order of the terms.
+// A device can match multiple terms.
+func DevicesMatchingTerms(ctx context.Context, selector v1alpha1.PhysicalVolumeSelector) ([][]string, error) {
 	if len(selector) == 0 {
 		return nil, nil
 	}
@@ -36,9 +56,9 @@ func DevicesMatchingSelector(ctx context.Context, selector v1alpha1.PhysicalVolu
 
 	log.FromContext(ctx).V(1).Info("devices discovered from LSBLK", "count", len(devices))
 
-	var selected []string
+	selected := make([][]string, len(selector))
 
-	for _, term := range selector {
+	for i, term := range selector {
 		for _, dev := range devices {
 			var matches int
 			for _, requirement := range term.MatchLSBLK {
@@ -53,16 +73,12 @@ func DevicesMatchingSelector(ctx context.Context, selector v1alpha1.PhysicalVolu
 				if kname, exists := dev.GetString(lsblk.ColumnPath); !exists {
 					return nil, fmt.Errorf("block device %s is missing path", kname)
 				} else {
-					selected = append(selected, kname)
+					selected[i] = append(selected[i], kname)
 				}
 			}
 		}
 	}
 
-	slices.Sort(selected)
-	// Remove duplicate matches
-	selected = slices.Compact(selected)
-
 	return selected, nil
 }
 
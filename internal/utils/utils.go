@@ -43,34 +43,6 @@ func Map[T any, R any](original []T, mapf func(T) R) []R {
 	return slice
 }
 
-// SequentialTwoWaySync synchronizes two slices of type T as desired and current.
-// It calls new with the elements that are in desired but not in current.
-// It calls old with the elements that are in current but not in desired.
-func SequentialTwoWaySync[T comparable](
-	desired []T,
-	current []T,
-	new func(diff []T) error,
-	old func(diff []T) error,
-) error {
-	var newFromA, oldFromB []T
-	newFromA = InLeftButNotInRight(desired, current)
-	oldFromB = InLeftButNotInRight(current, desired)
-
-	if len(newFromA) > 0 {
-		if err := new(newFromA); err != nil {
-			return err
-		}
-	}
-
-	if len(oldFromB) > 0 {
-		if err := old(oldFromB); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 